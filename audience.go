@@ -0,0 +1,205 @@
+package scalarui
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AudienceSelector names the audience a request belongs to, such as
+// "public", "partner" or "internal"
+type AudienceSelector func(r *http.Request) string
+
+// Audience describes one view of the docs. Filters only apply to inline
+// documents; remove URL sources an audience must not see in Configure.
+type Audience struct {
+	Filter    *SpecFilter   // Applied to Content and every source, on top of their own filters
+	Configure func(*Config) // Adjusts this audience's copy of the config
+}
+
+// Audiences serves a different view of the same docs to each audience. Every
+// view is a ScalarUI of its own, so pages and documents are rendered and
+// cached separately per audience. Requests whose audience has no view get
+// 404 Not Found.
+type Audiences struct {
+	selector AudienceSelector
+
+	mu        sync.RWMutex
+	base      *Config
+	audiences map[string]Audience
+	views     map[string]*ScalarUI
+}
+
+// NewAudiences creates audience views of config, chosen per request by
+// selector
+func NewAudiences(config *Config, selector AudienceSelector) *Audiences {
+	if config == nil {
+		config = NewConfig()
+	}
+	return &Audiences{
+		selector:  selector,
+		base:      config.Clone(),
+		audiences: make(map[string]Audience),
+		views:     make(map[string]*ScalarUI),
+	}
+}
+
+// Add creates or replaces the view for the named audience
+func (a *Audiences) Add(name string, audience Audience) *Audiences {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.audiences[name] = audience
+	if view, ok := a.views[name]; ok {
+		view.SetConfig(audienceConfig(a.base, audience))
+		return a
+	}
+	view := New(audienceConfig(a.base, audience))
+	view.private = true
+	a.views[name] = view
+	return a
+}
+
+// View returns the ScalarUI serving the named audience, or nil. Use it to
+// install an AuthProvider or read Warnings; its config is rebuilt whenever
+// the Audiences config changes.
+func (a *Audiences) View(name string) *ScalarUI {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.views[name]
+}
+
+// Names returns the audiences with a view, sorted
+func (a *Audiences) Names() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	names := make([]string, 0, len(a.views))
+	for name := range a.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetConfig replaces the shared configuration and rebuilds every view
+func (a *Audiences) SetConfig(config *Config) {
+	if config == nil {
+		config = NewConfig()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.base = config.Clone()
+	a.rebuild()
+}
+
+// Update applies fn to the shared configuration and rebuilds every view
+func (a *Audiences) Update(fn func(*Config)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	config := a.base.Clone()
+	fn(config)
+	a.base = config
+	a.rebuild()
+}
+
+// GetConfig returns a copy of the shared configuration
+func (a *Audiences) GetConfig() *Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.base.Clone()
+}
+
+// Reload bumps the hot-reload version of every view
+func (a *Audiences) Reload() {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, view := range a.views {
+		view.Reload()
+	}
+}
+
+// Watch re-reads file-backed specs in the shared configuration whenever
+// w reports a change, rebuilds every view and reloads open pages
+func (a *Audiences) Watch(ctx context.Context, w *Watcher) error {
+	return w.Run(ctx, func() {
+		a.Update(func(c *Config) {
+			c.Content = reloadSpec(c.Content)
+			for i := range c.Sources {
+				c.Sources[i].Content = reloadSpec(c.Sources[i].Content)
+			}
+		})
+		a.Reload()
+	})
+}
+
+// ServeHTTP renders the page of the request's audience
+func (a *Audiences) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	view := a.selected(r)
+	if view == nil {
+		http.NotFound(w, r)
+		return
+	}
+	view.ServeHTTP(w, r)
+}
+
+// Mount returns a handler serving the docs subtree of the request's
+// audience below prefix; see ScalarUI.Mount for the routes
+func (a *Audiences) Mount(prefix string) http.Handler {
+	prefix = strings.TrimRight(prefix, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		view := a.selected(r)
+		if view == nil {
+			http.NotFound(w, r)
+			return
+		}
+		view.Mount(prefix).ServeHTTP(w, r)
+	})
+}
+
+// selected returns the view for the request's audience, or nil
+func (a *Audiences) selected(r *http.Request) *ScalarUI {
+	return a.View(a.selector(r))
+}
+
+// rebuild gives every view a fresh config built from base
+func (a *Audiences) rebuild() {
+	for name, view := range a.views {
+		view.SetConfig(audienceConfig(a.base, a.audiences[name]))
+	}
+}
+
+// audienceConfig returns a copy of base adjusted for audience. Content is
+// filtered after Configure so nothing it adds escapes the filter; a document
+// that cannot be filtered is left out.
+func audienceConfig(base *Config, audience Audience) *Config {
+	config := base.Clone()
+	if audience.Configure != nil {
+		audience.Configure(config)
+	}
+	if audience.Filter == nil {
+		return config
+	}
+
+	narrow := func(content interface{}) interface{} {
+		// Upgrade first so the filtered spec keeps the upgrade warnings
+		if upgraded, err := upgradeContent(content); err == nil && upgraded != nil {
+			content = upgraded
+		}
+		spec, err := filterContent(content, audience.Filter)
+		if err != nil {
+			return nil
+		}
+		if spec == nil {
+			return content
+		}
+		return spec
+	}
+	config.Content = narrow(config.Content)
+	for i := range config.Sources {
+		config.Sources[i].Content = narrow(config.Sources[i].Content)
+	}
+	return config
+}
@@ -0,0 +1,69 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAudiences(t *testing.T) {
+	spec, err := LoadSpecFile("testdata/specs/filter.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	config := NewConfig().WithHideTestRequestButton(true)
+	config.Content = spec
+
+	audiences := NewAudiences(config, func(r *http.Request) string {
+		return r.Header.Get("X-Audience")
+	}).
+		Add("public", Audience{Filter: &SpecFilter{ExcludeInternal: true}}).
+		Add("internal", Audience{Configure: func(c *Config) {
+			c.HideTestRequestButton = nil
+			c.Title = "Internal Docs"
+		}})
+	handler := audiences.Mount("/docs")
+
+	get := func(audience, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Audience", audience)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	public, internal := get("public", "/docs").Body.String(), get("internal", "/docs").Body.String()
+	if !strings.Contains(public, `"hideTestRequestButton": true`) || strings.Contains(public, "Internal Docs") {
+		t.Errorf("public page does not use the shared config:\n%s", public)
+	}
+	if strings.Contains(internal, "hideTestRequestButton") || !strings.Contains(internal, "Internal Docs") {
+		t.Errorf("internal page does not use its config variant:\n%s", internal)
+	}
+
+	if doc := get("public", "/docs/openapi.yaml").Body.String(); strings.Contains(doc, "listFlags") {
+		t.Errorf("public document contains internal operations:\n%s", doc)
+	}
+	rec := get("internal", "/docs/openapi.yaml")
+	if !strings.Contains(rec.Body.String(), "listFlags") {
+		t.Errorf("internal document was filtered:\n%s", rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache, private" {
+		t.Errorf("Cache-Control = %q, want no-cache, private", cc)
+	}
+	if rec := get("partner", "/docs"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET for unknown audience = %d, want 404", rec.Code)
+	}
+
+	// Views are rebuilt, and re-rendered, when the shared config changes
+	audiences.Update(func(c *Config) { c.Title = "Accounts" })
+	if page := get("public", "/docs").Body.String(); !strings.Contains(page, "<title>Accounts</title>") {
+		t.Errorf("public page was not rebuilt")
+	}
+	if page := get("internal", "/docs").Body.String(); !strings.Contains(page, "Internal Docs") {
+		t.Errorf("internal page lost its config variant")
+	}
+	if w := audiences.View("public").Warnings(); len(w) != 2 {
+		t.Errorf("public Warnings = %q", w)
+	}
+}
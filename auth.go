@@ -0,0 +1,365 @@
+package scalarui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// AuthConfig is a typed builder for Scalar's authentication block. Scheme
+// names must match the security schemes declared in the spec.
+//
+//	auth := scalarui.NewAuth().
+//		WithBearer("BearerAuth", token).
+//		WithAPIKey("ApiKeyAuth", key).
+//		Prefer("BearerAuth")
+//	config, err := config.WithAuth(auth)
+type AuthConfig struct {
+	PreferredSecurityScheme string                    `json:"preferredSecurityScheme,omitempty"`
+	SecuritySchemes         map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is the prefilled state of one spec security scheme:
+// *HTTPBasic, *HTTPBearer, *APIKey or *OAuth2
+type SecurityScheme interface {
+	// specType returns the OpenAPI type (and HTTP scheme) it applies to
+	specType() (typ, httpScheme string)
+}
+
+// HTTPBasic prefills an http "basic" scheme
+type HTTPBasic struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// HTTPBearer prefills an http "bearer" scheme
+type HTTPBearer struct {
+	Token string `json:"token,omitempty"`
+}
+
+// APIKeyLocation is where an API key is sent
+type APIKeyLocation string
+
+const (
+	APIKeyInHeader APIKeyLocation = "header"
+	APIKeyInQuery  APIKeyLocation = "query"
+	APIKeyInCookie APIKeyLocation = "cookie"
+)
+
+// APIKey prefills an apiKey scheme. Name and In default to the spec's.
+type APIKey struct {
+	Name  string         `json:"name,omitempty"` // Header, query or cookie name
+	In    APIKeyLocation `json:"in,omitempty"`
+	Value string         `json:"value,omitempty"`
+}
+
+// PKCE is the code challenge method for the authorization code flow
+type PKCE string
+
+const (
+	PKCESHA256 PKCE = "SHA-256"
+	PKCEPlain  PKCE = "plain"
+	PKCENone   PKCE = "no"
+)
+
+// OAuth2 prefills an oauth2 scheme
+type OAuth2 struct {
+	Flows         OAuth2Flows `json:"flows"`
+	DefaultScopes []string    `json:"x-default-scopes,omitempty"`
+}
+
+// OAuth2Flows holds one entry per flow to prefill; nil flows are left alone
+type OAuth2Flows struct {
+	AuthorizationCode *AuthorizationCodeFlow `json:"authorizationCode,omitempty"`
+	ClientCredentials *ClientCredentialsFlow `json:"clientCredentials,omitempty"`
+	Implicit          *ImplicitFlow          `json:"implicit,omitempty"`
+	Password          *PasswordFlow          `json:"password,omitempty"`
+}
+
+// AuthorizationCodeFlow prefills the authorization code flow
+type AuthorizationCodeFlow struct {
+	ClientID         string   `json:"x-scalar-client-id,omitempty"`
+	ClientSecret     string   `json:"clientSecret,omitempty"`
+	AuthorizationURL string   `json:"authorizationUrl,omitempty"` // Overrides the spec
+	TokenURL         string   `json:"tokenUrl,omitempty"`         // Overrides the spec
+	RedirectURI      string   `json:"x-scalar-redirect-uri,omitempty"`
+	PKCE             PKCE     `json:"x-usePkce,omitempty"`
+	SelectedScopes   []string `json:"selectedScopes,omitempty"`
+	Token            string   `json:"token,omitempty"`
+}
+
+// ClientCredentialsFlow prefills the client credentials flow
+type ClientCredentialsFlow struct {
+	ClientID       string   `json:"x-scalar-client-id,omitempty"`
+	ClientSecret   string   `json:"clientSecret,omitempty"`
+	TokenURL       string   `json:"tokenUrl,omitempty"` // Overrides the spec
+	SelectedScopes []string `json:"selectedScopes,omitempty"`
+	Token          string   `json:"token,omitempty"`
+}
+
+// ImplicitFlow prefills the implicit flow
+type ImplicitFlow struct {
+	ClientID         string   `json:"x-scalar-client-id,omitempty"`
+	AuthorizationURL string   `json:"authorizationUrl,omitempty"` // Overrides the spec
+	RedirectURI      string   `json:"x-scalar-redirect-uri,omitempty"`
+	SelectedScopes   []string `json:"selectedScopes,omitempty"`
+	Token            string   `json:"token,omitempty"`
+}
+
+// PasswordFlow prefills the resource owner password flow
+type PasswordFlow struct {
+	ClientID       string   `json:"x-scalar-client-id,omitempty"`
+	ClientSecret   string   `json:"clientSecret,omitempty"`
+	TokenURL       string   `json:"tokenUrl,omitempty"` // Overrides the spec
+	Username       string   `json:"username,omitempty"`
+	Password       string   `json:"password,omitempty"`
+	SelectedScopes []string `json:"selectedScopes,omitempty"`
+	Token          string   `json:"token,omitempty"`
+}
+
+func (*HTTPBasic) specType() (string, string)  { return "http", "basic" }
+func (*HTTPBearer) specType() (string, string) { return "http", "bearer" }
+func (*APIKey) specType() (string, string)     { return "apiKey", "" }
+func (*OAuth2) specType() (string, string)     { return "oauth2", "" }
+
+// NewAuth creates an empty authentication config
+func NewAuth() *AuthConfig {
+	return &AuthConfig{SecuritySchemes: make(map[string]SecurityScheme)}
+}
+
+// Prefer selects the scheme shown first in the Try-It auth picker
+func (a *AuthConfig) Prefer(name string) *AuthConfig {
+	a.PreferredSecurityScheme = name
+	return a
+}
+
+// WithScheme prefills the named spec security scheme
+func (a *AuthConfig) WithScheme(name string, scheme SecurityScheme) *AuthConfig {
+	if a.SecuritySchemes == nil {
+		a.SecuritySchemes = make(map[string]SecurityScheme)
+	}
+	a.SecuritySchemes[name] = scheme
+	return a
+}
+
+// WithBasic prefills an http basic scheme
+func (a *AuthConfig) WithBasic(name, username, password string) *AuthConfig {
+	return a.WithScheme(name, &HTTPBasic{Username: username, Password: password})
+}
+
+// WithBearer prefills an http bearer scheme
+func (a *AuthConfig) WithBearer(name, token string) *AuthConfig {
+	return a.WithScheme(name, &HTTPBearer{Token: token})
+}
+
+// WithAPIKey prefills an apiKey scheme, keeping the spec's name and location
+func (a *AuthConfig) WithAPIKey(name, value string) *AuthConfig {
+	return a.WithScheme(name, &APIKey{Value: value})
+}
+
+// WithOAuth2 prefills an oauth2 scheme
+func (a *AuthConfig) WithOAuth2(name string, flows OAuth2Flows) *AuthConfig {
+	return a.WithScheme(name, &OAuth2{Flows: flows})
+}
+
+// Map returns the block in the shape Config.Authentication holds. It fails
+// when a scheme cannot be marshaled, such as a type embedding one of ours
+// with a MarshalJSON method of its own.
+func (a *AuthConfig) Map() (map[string]interface{}, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: authentication: %w", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("scalarui: authentication: %w", err)
+	}
+	return m, nil
+}
+
+// Validate checks the block against the security schemes spec declares
+func (a *AuthConfig) Validate(spec *Spec) error {
+	if spec == nil {
+		return errors.New("scalarui: validate authentication: nil spec")
+	}
+	m, err := a.Map()
+	if err != nil {
+		return err
+	}
+	return validateAuthentication(m, securitySchemes(spec.Doc)).orNil()
+}
+
+// WithAuth sets the authentication block from a typed config. On error the
+// config is left unchanged.
+func (c *Config) WithAuth(auth *AuthConfig) (*Config, error) {
+	m, err := auth.Map()
+	if err != nil {
+		return c, err
+	}
+	c.Authentication = m
+	return c, nil
+}
+
+/* ------------------------------------------------------------- */
+/* Per-request Prefill */
+/* ------------------------------------------------------------- */
+
+// AuthProvider returns credentials to prefill for the user making r, such as
+// a token taken from their session. It returns nil for anonymous requests.
+// An error fails the request with 500.
+type AuthProvider func(r *http.Request) (*AuthConfig, error)
+
+// SetAuthProvider installs p, or removes the provider when p is nil.
+//
+// The returned schemes are merged into a copy of Config.Authentication for
+// that response only. Prefilled pages are never cached, and while a provider
+// is installed every page is sent with "Cache-Control: no-store, private"
+// and no ETag, so no user's page is reused for another.
+func (s *ScalarUI) SetAuthProvider(p AuthProvider) {
+	if p == nil {
+		s.auth.Store(nil)
+		return
+	}
+	s.auth.Store(&p)
+}
+
+// prefilledConfig returns a copy of config with auth merged into its
+// authentication block
+func prefilledConfig(config *Config, auth *AuthConfig) (*Config, error) {
+	m, err := auth.Map()
+	if err != nil {
+		return nil, err
+	}
+	return config.Clone().Merge(&Config{Authentication: m}), nil
+}
+
+/* ------------------------------------------------------------- */
+/* Validation */
+/* ------------------------------------------------------------- */
+
+// oauth2FlowNames maps Scalar flow names to their Swagger 2.0 equivalents
+var oauth2FlowNames = map[string]string{
+	"authorizationCode": "accessCode",
+	"clientCredentials": "application",
+	"implicit":          "implicit",
+	"password":          "password",
+}
+
+// validateAuthentication checks a Config.Authentication block against the
+// declared schemes. Errors point into the config, e.g.
+// /authentication/securitySchemes/BearerAuth.
+func validateAuthentication(auth map[string]interface{}, declared map[string]interface{}) ValidationErrors {
+	var errs ValidationErrors
+	addf := func(ptr, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	known := strings.Join(names, ", ")
+	if known == "" {
+		known = "none"
+	}
+
+	var preferred []interface{}
+	switch p := auth["preferredSecurityScheme"].(type) {
+	case string:
+		preferred = []interface{}{p}
+	case []interface{}:
+		preferred = p
+	}
+	for _, p := range preferred {
+		if name, ok := p.(string); ok && declared[name] == nil {
+			addf("/authentication/preferredSecurityScheme", "security scheme %q is not declared (declared: %s)", name, known)
+		}
+	}
+
+	configured, _ := auth["securitySchemes"].(map[string]interface{})
+	for _, name := range sortedKeys(configured) {
+		ptr := pointer("/authentication/securitySchemes", name)
+		specScheme, ok := declared[name].(map[string]interface{})
+		if !ok {
+			addf(ptr, "security scheme %q is not declared (declared: %s)", name, known)
+			continue
+		}
+		prefill, ok := configured[name].(map[string]interface{})
+		if !ok {
+			addf(ptr, "must be an object")
+			continue
+		}
+		checkSchemeKind(ptr, prefill, specScheme, addf)
+	}
+	return errs
+}
+
+// checkSchemeKind compares the fields a prefill uses with the spec scheme type
+func checkSchemeKind(ptr string, prefill, spec map[string]interface{}, addf func(string, string, ...interface{})) {
+	typ, _ := spec["type"].(string)
+	httpScheme, _ := spec["scheme"].(string)
+	httpScheme = strings.ToLower(httpScheme)
+	if typ == "basic" {
+		// Swagger 2.0
+		typ, httpScheme = "http", "basic"
+	}
+
+	_, hasFlows := prefill["flows"]
+	_, hasValue := prefill["value"]
+	_, hasUser := prefill["username"]
+	_, hasToken := prefill["token"]
+
+	switch {
+	case hasFlows:
+		if typ != "oauth2" {
+			addf(ptr, "oauth2 flows configured for a %q scheme", typ)
+			return
+		}
+		checkOAuth2Flows(ptr+"/flows", prefill["flows"], spec["flows"], spec["flow"], addf)
+	case hasValue:
+		if typ != "apiKey" {
+			addf(ptr, "API key configured for a %q scheme", typ)
+			return
+		}
+		if in, ok := prefill["in"].(string); ok && in != spec["in"] {
+			addf(ptr+"/in", "is %q but the spec sends the key in %v", in, spec["in"])
+		}
+	case hasUser:
+		if typ != "http" || httpScheme != "basic" {
+			addf(ptr, "basic credentials configured for a %q scheme", describeScheme(typ, httpScheme))
+		}
+	case hasToken:
+		if typ != "http" || httpScheme != "bearer" {
+			addf(ptr, "bearer token configured for a %q scheme", describeScheme(typ, httpScheme))
+		}
+	}
+}
+
+// checkOAuth2Flows checks each configured flow exists in the spec scheme.
+// Swagger 2.0 declares a single flow by its old name.
+func checkOAuth2Flows(ptr string, prefill, specFlows, swaggerFlow interface{}, addf func(string, string, ...interface{})) {
+	flows, _ := prefill.(map[string]interface{})
+	declared, _ := specFlows.(map[string]interface{})
+
+	for _, name := range sortedKeys(flows) {
+		p := pointer(ptr, name)
+		if declared[name] == nil && swaggerFlow != oauth2FlowNames[name] {
+			addf(p, "flow %q is not declared by the scheme", name)
+		}
+		flow, _ := flows[name].(map[string]interface{})
+		if pkce, ok := flow["x-usePkce"].(string); ok && !contains([]string{"SHA-256", "plain", "no"}, pkce) {
+			addf(p+"/x-usePkce", "invalid value %q (want SHA-256|plain|no)", pkce)
+		}
+	}
+}
+
+func describeScheme(typ, httpScheme string) string {
+	if typ == "http" && httpScheme != "" {
+		return "http " + httpScheme
+	}
+	return typ
+}
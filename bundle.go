@@ -0,0 +1,76 @@
+package scalarui
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+)
+
+// ScalarCDN is the jsdelivr URL of the Scalar API reference package
+const ScalarCDN = "https://cdn.jsdelivr.net/npm/@scalar/api-reference"
+
+// BundlePath is where Mount serves a self-hosted bundle below its prefix
+const BundlePath = "/scalar.js"
+
+// Bundle is a self-hosted copy of the Scalar API reference script
+type Bundle struct {
+	Version   string // Scalar package version the bundle was built from
+	Integrity string // Subresource Integrity hash (sha384-...)
+	data      []byte
+	digest    string // Hex prefix of the content hash, busts caches in script URLs
+}
+
+// NewBundle wraps the given script and computes its SRI hash
+func NewBundle(version string, data []byte) *Bundle {
+	sum := sha512.Sum384(data)
+	return &Bundle{
+		Version:   version,
+		Integrity: "sha384-" + base64.StdEncoding.EncodeToString(sum[:]),
+		data:      data,
+		digest:    hex.EncodeToString(sum[:8]),
+	}
+}
+
+// LoadBundle reads the script from fsys, typically an embed.FS
+func LoadBundle(fsys fs.FS, name, version string) (*Bundle, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: load bundle: %w", err)
+	}
+	return NewBundle(version, data), nil
+}
+
+// Bytes returns the script contents
+func (b *Bundle) Bytes() []byte {
+	return b.data
+}
+
+// ServeHTTP serves the script with long-lived caching; page URLs carry a
+// hash of its contents so a new bundle is picked up immediately, even when
+// Version was not bumped
+func (b *Bundle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+b.Integrity+`"`)
+	http.ServeContent(w, r, "scalar.js", time.Time{}, bytes.NewReader(b.data))
+}
+
+// scriptSource returns the src and integrity of the Scalar script tag.
+// basePath is the Mount prefix a self-hosted bundle is served under.
+func scriptSource(config *Config, basePath string) (string, string) {
+	switch {
+	case config.ScriptURL != "":
+		return config.ScriptURL, config.ScriptIntegrity
+	case config.Bundle != nil:
+		return basePath + BundlePath + "?v=" + config.Bundle.digest, config.Bundle.Integrity
+	case config.ScalarVersion != "":
+		return ScalarCDN + "@" + config.ScalarVersion, config.ScriptIntegrity
+	default:
+		return ScalarCDN, config.ScriptIntegrity
+	}
+}
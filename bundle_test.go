@@ -0,0 +1,81 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadBundle(t *testing.T) {
+	fsys := fstest.MapFS{"scalar/standalone.js": {Data: []byte("// scalar")}}
+
+	bundle, err := LoadBundle(fsys, "scalar/standalone.js", "1.28.0")
+	if err != nil {
+		t.Fatalf("LoadBundle: %v", err)
+	}
+	if string(bundle.Bytes()) != "// scalar" || !strings.HasPrefix(bundle.Integrity, "sha384-") {
+		t.Errorf("bundle = %q %s", bundle.Bytes(), bundle.Integrity)
+	}
+	if _, err := LoadBundle(fsys, "missing.js", "1.28.0"); err == nil {
+		t.Errorf("LoadBundle of a missing file succeeded")
+	}
+}
+
+func TestServeBundle(t *testing.T) {
+	bundle := NewBundle("1.28.0+local", []byte("// scalar"))
+	ui := New(NewConfig().WithBundle(bundle))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		page    string
+		script  string
+	}{
+		{"ServeHTTP", ui, "/docs", "/scalar.js"},
+		{"Mount", ui.Mount("/docs"), "/docs", "/docs/scalar.js"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := func(path, etag string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				rec := httptest.NewRecorder()
+				tt.handler.ServeHTTP(rec, req)
+				return rec
+			}
+
+			src := `src="` + tt.script + `?v=` + bundle.digest + `" integrity="` + bundle.Integrity + `"`
+			if page := get(tt.page, "").Body.String(); !strings.Contains(page, src) {
+				t.Errorf("page does not load %s:\n%s", src, page)
+			}
+
+			rec := get(tt.script, "")
+			if ct := rec.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" || rec.Body.String() != "// scalar" {
+				t.Errorf("GET %s = %s %q", tt.script, ct, rec.Body.String())
+			}
+			if rec := get(tt.script, rec.Header().Get("ETag")); rec.Code != http.StatusNotModified {
+				t.Errorf("conditional GET %s = %d, want 304", tt.script, rec.Code)
+			}
+		})
+	}
+}
+
+func TestBundleURLFollowsContent(t *testing.T) {
+	before := NewBundle("1.28.0", []byte("// scalar"))
+	after := NewBundle("1.28.0", []byte("// scalar, rebuilt"))
+
+	src := func(b *Bundle) string {
+		url, _ := scriptSource(NewConfig().WithBundle(b), "/docs")
+		return url
+	}
+	if src(before) == src(after) {
+		t.Errorf("changed bundle with the same version keeps the URL %s", src(before))
+	}
+	if src(before) != src(NewBundle("1.29.0", []byte("// scalar"))) {
+		t.Errorf("bundle URL depends on the version, not the content")
+	}
+}
@@ -0,0 +1,196 @@
+package scalarui
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// renderedPage is a cached rendering of the page
+type renderedPage struct {
+	html []byte
+	etag string // Strong ETag of html
+}
+
+// pageKey identifies a cached rendering; output only depends on the mount
+// options and on whether nonce attributes are present
+type pageKey struct {
+	basePath  string
+	mounted   bool
+	hotReload bool
+	nonce     bool
+}
+
+// snapshot is an immutable config together with the pages rendered from it.
+// Replacing the snapshot drops its cache, so a render that races with
+// SetConfig can never store a stale page.
+type snapshot struct {
+	config   *Config  // As set by the caller
+	served   *Config  // config with its documents upgraded and filtered
+	warnings []string // Swagger 2.0 upgrade and filter warnings
+
+	// Origins of the servers the served documents declare, for connect-src
+	specServers []string
+
+	// Stands in for the per-request nonce in cached pages. It is random, so
+	// no title or document text can receive the nonce.
+	nonceMarker string
+
+	mu        sync.Mutex
+	pages     map[pageKey]*renderedPage
+	documents map[documentKey][]byte
+}
+
+// documentKey identifies a served document, converted if needed
+type documentKey struct {
+	slug   string
+	format SpecFormat
+}
+
+// newSnapshot takes ownership of config, which is left as it is. Pages and
+// documents are served from a copy with Swagger 2.0 content upgraded to
+// OpenAPI 3.0 and filters applied.
+func newSnapshot(config *Config) *snapshot {
+	served := *config
+	served.Sources = append([]SourceConfig(nil), config.Sources...)
+	s := &snapshot{config: config, served: &served, nonceMarker: "__scalarui_nonce_" + rand.Text()}
+
+	prepare := func(name string, content *interface{}, filter *SpecFilter) {
+		upgraded, err := upgradeContent(*content)
+		if err != nil {
+			s.warnings = append(s.warnings, name+": "+err.Error())
+		} else if upgraded != nil {
+			*content = upgraded
+		}
+
+		filtered, err := filterContent(*content, filter)
+		if err != nil {
+			// Never publish what the filter should have removed
+			s.warnings = append(s.warnings, name+": "+err.Error()+"; document dropped")
+			*content = nil
+			return
+		} else if filtered != nil {
+			*content = filtered
+		}
+
+		// Specs upgraded or filtered earlier carry their warnings along
+		if spec, ok := (*content).(*Spec); ok {
+			for _, w := range spec.Warnings {
+				s.warnings = append(s.warnings, name+": "+w)
+			}
+		}
+		s.specServers = append(s.specServers, serverOrigins(*content)...)
+	}
+	prepare("content", &served.Content, served.Filter)
+	for i := range served.Sources {
+		filter := served.Sources[i].Filter
+		if filter == nil {
+			filter = served.Filter
+		}
+		prepare(fmt.Sprintf("sources[%d]", i), &served.Sources[i].Content, filter)
+	}
+	return s
+}
+
+// Warnings lists what was lost upgrading Swagger 2.0 documents to OpenAPI
+// 3.0 and what filters had to drop, each prefixed with the document
+// ("content" or "sources[i]")
+func (s *ScalarUI) Warnings() []string {
+	return append([]string(nil), s.current.Load().warnings...)
+}
+
+// Invalidate drops cached renderings and prepares the documents again.
+// SetConfig and Update do this already; it is only needed when a shared
+// *Spec or *Bundle changed in place.
+func (s *ScalarUI) Invalidate() {
+	s.updateMu.Lock()
+	current := s.current.Load()
+	s.current.Store(newSnapshot(current.config))
+	s.updateMu.Unlock()
+}
+
+// page returns the rendered HTML for opts, rendering and caching it on first
+// use. A nonce is substituted into a cached copy rendered with nonceMarker.
+func (s *snapshot) page(opts RenderOptions) ([]byte, string, error) {
+	key := pageKey{basePath: opts.basePath, mounted: opts.mounted, hotReload: opts.hotReload, nonce: opts.Nonce != ""}
+
+	s.mu.Lock()
+	cached, ok := s.pages[key]
+	s.mu.Unlock()
+
+	if !ok {
+		renderOpts := opts
+		if key.nonce {
+			renderOpts.Nonce = s.nonceMarker
+		}
+		out, err := renderTemplate(s.served, renderOpts)
+		if err != nil {
+			return nil, "", err
+		}
+		sum := sha256.Sum256([]byte(out))
+		cached = &renderedPage{
+			html: []byte(out),
+			etag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+		}
+
+		s.mu.Lock()
+		if s.pages == nil {
+			s.pages = make(map[pageKey]*renderedPage)
+		}
+		s.pages[key] = cached
+		s.mu.Unlock()
+	}
+
+	if key.nonce {
+		nonce := []byte(html.EscapeString(opts.Nonce))
+		return bytes.ReplaceAll(cached.html, []byte(s.nonceMarker), nonce), "", nil
+	}
+	return cached.html, cached.etag, nil
+}
+
+// document returns the inline document at slug in format, converting and
+// caching it on first use
+func (s *snapshot) document(slug string, format SpecFormat) ([]byte, error) {
+	key := documentKey{slug: slug, format: format}
+
+	s.mu.Lock()
+	data, ok := s.documents[key]
+	s.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := encodeContent(documentContent(s.served, slug), format)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.documents == nil {
+		s.documents = make(map[documentKey][]byte)
+	}
+	s.documents[key] = data
+	s.mu.Unlock()
+	return data, nil
+}
+
+// notModified reports whether the request's If-None-Match matches etag
+func notModified(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package scalarui
+
+import "reflect"
+
+// sharedTypes are immutable once built and shared between clones
+var sharedTypes = map[reflect.Type]bool{
+	reflect.TypeOf((*Spec)(nil)):   true,
+	reflect.TypeOf((*Bundle)(nil)): true,
+	reflect.TypeOf((*Proxy)(nil)):  true,
+}
+
+// Clone returns a deep copy of the config. Maps, slices and nested values
+// are copied; loaded *Spec and *Bundle values are immutable and shared, as
+// is the *Proxy handler.
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(c)).Interface().(*Config)
+}
+
+// deepCopy recursively copies pointers, interfaces, maps, slices and structs
+func deepCopy(v reflect.Value) reflect.Value {
+	return deepCopyFunc(v, nil)
+}
+
+// deepCopyFunc is deepCopy, except that values for which replace reports
+// true are swapped for its result
+func deepCopyFunc(v reflect.Value, replace func(reflect.Value) (reflect.Value, bool)) reflect.Value {
+	if replace != nil && v.IsValid() {
+		if out, ok := replace(v); ok {
+			return out
+		}
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || sharedTypes[v.Type()] {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(deepCopyFunc(v.Elem(), replace))
+		return out
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(deepCopyFunc(v.Elem(), replace))
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), deepCopyFunc(iter.Value(), replace))
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyFunc(v.Index(i), replace))
+		}
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopyFunc(v.Field(i), replace))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// MergeOption changes how Merge combines fields
+type MergeOption int
+
+const (
+	// AppendSlices appends override slices (Servers, Sources, Plugins, ...)
+	// instead of replacing them
+	AppendSlices MergeOption = iota + 1
+)
+
+// Merge applies the fields set in other on top of c and returns c:
+//
+//   - fields left at their zero value in other are ignored; set a
+//     boolean to Bool(false) to turn a feature off
+//   - maps are merged key-wise, nested objects recursively
+//   - slices replace c's slices, or are appended with AppendSlices
+//   - everything else replaces c's value
+//
+// Values are copied from other, so the two configs share no state. Use
+// base.Clone().Merge(override) to keep base unchanged.
+func (c *Config) Merge(other *Config, opts ...MergeOption) *Config {
+	if other == nil {
+		return c
+	}
+	appendSlices := false
+	for _, opt := range opts {
+		if opt == AppendSlices {
+			appendSlices = true
+		}
+	}
+
+	dst := reflect.ValueOf(c).Elem()
+	src := reflect.ValueOf(other).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).CanSet() {
+			mergeValue(dst.Field(i), src.Field(i), appendSlices)
+		}
+	}
+	return c
+}
+
+// mergeValue merges src into the settable dst
+func mergeValue(dst, src reflect.Value, appendSlices bool) {
+	if src.IsZero() {
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			key, value := iter.Key(), iter.Value()
+			if existing := dst.MapIndex(key); existing.IsValid() {
+				dstMap, ok1 := existing.Interface().(map[string]interface{})
+				srcMap, ok2 := value.Interface().(map[string]interface{})
+				if ok1 && ok2 && dstMap != nil {
+					mergeMaps(dstMap, srcMap)
+					continue
+				}
+			}
+			dst.SetMapIndex(key, deepCopy(value))
+		}
+
+	case reflect.Slice:
+		if appendSlices {
+			dst.Set(reflect.AppendSlice(dst, deepCopy(src)))
+		} else {
+			dst.Set(deepCopy(src))
+		}
+
+	default:
+		dst.Set(deepCopy(src))
+	}
+}
+
+// mergeMaps merges nested JSON-like objects key-wise
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok && dstMap != nil {
+				mergeMaps(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = deepCopy(reflect.ValueOf(&value).Elem()).Interface()
+	}
+}
@@ -0,0 +1,100 @@
+package scalarui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloneIsIndependent(t *testing.T) {
+	spec, err := LoadSpecFile("demo/data/openapi.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	base := NewConfig().
+		WithSpec(spec).
+		WithVariable("accent", "red").
+		WithServer("https://api.example.com", "Prod").
+		WithAuthentication(map[string]interface{}{
+			"securitySchemes": map[string]interface{}{"bearer": map[string]interface{}{"token": "a"}},
+		})
+
+	clone := base.Clone()
+	clone.Variables["accent"] = "blue"
+	clone.Servers[0].URL = "https://changed.example.com"
+	clone.Authentication["securitySchemes"].(map[string]interface{})["bearer"].(map[string]interface{})["token"] = "b"
+
+	if base.Variables["accent"] != "red" || base.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("Clone shares maps or slices with the original")
+	}
+	if token := base.Authentication["securitySchemes"].(map[string]interface{})["bearer"].(map[string]interface{})["token"]; token != "a" {
+		t.Errorf("Clone shares nested authentication maps, token = %v", token)
+	}
+	if clone.Content != base.Content {
+		t.Errorf("Clone copied the immutable *Spec")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := NewConfig().
+		WithTheme("purple").
+		WithVariable("accent", "red").
+		WithVariable("font", "Inter").
+		WithServer("https://api.example.com", "Prod").
+		WithAuthentication(map[string]interface{}{
+			"preferredSecurityScheme": "bearer",
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"token": "prod"},
+				"apiKey": map[string]interface{}{"value": "k"},
+			},
+		})
+
+	staging := &Config{
+		Title:     "Staging",
+		Variables: map[string]string{"accent": "orange"},
+		Servers:   []Server{{URL: "https://staging.example.com", Description: "Staging"}},
+		Authentication: map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"token": "staging"},
+			},
+		},
+	}
+
+	merged := base.Clone().Merge(staging)
+
+	if merged.Theme != "purple" || merged.Title != "Staging" {
+		t.Errorf("Theme/Title = %q/%q, want purple/Staging", merged.Theme, merged.Title)
+	}
+	if want := map[string]string{"accent": "orange", "font": "Inter"}; !reflect.DeepEqual(merged.Variables, want) {
+		t.Errorf("Variables = %v, want %v", merged.Variables, want)
+	}
+	if len(merged.Servers) != 1 || merged.Servers[0].Description != "Staging" {
+		t.Errorf("Servers = %v, want only staging", merged.Servers)
+	}
+	wantAuth := map[string]interface{}{
+		"preferredSecurityScheme": "bearer",
+		"securitySchemes": map[string]interface{}{
+			"bearer": map[string]interface{}{"token": "staging"},
+			"apiKey": map[string]interface{}{"value": "k"},
+		},
+	}
+	if !reflect.DeepEqual(merged.Authentication, wantAuth) {
+		t.Errorf("Authentication = %v, want %v", merged.Authentication, wantAuth)
+	}
+	if base.Variables["accent"] != "red" || base.Title != "" {
+		t.Errorf("Merge on a clone changed the base config")
+	}
+
+	if hidden := base.Clone().Merge(&Config{ShowSidebar: Bool(false)}); *hidden.ShowSidebar {
+		t.Errorf("Merge ignored ShowSidebar: Bool(false)")
+	}
+
+	appended := base.Clone().Merge(staging, AppendSlices)
+	if len(appended.Servers) != 2 {
+		t.Errorf("AppendSlices: got %d servers, want 2", len(appended.Servers))
+	}
+
+	staging.Variables["accent"] = "green"
+	if merged.Variables["accent"] != "orange" {
+		t.Errorf("Merge shares maps with the override")
+	}
+}
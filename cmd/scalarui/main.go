@@ -0,0 +1,111 @@
+// Command scalarui works with Scalar docs without running a server.
+//
+//	scalarui export -spec openapi.yaml -config docs.yaml -out site
+//
+// export writes a static site that can be published to S3, GitHub Pages or
+// any other file host.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nyxstack/scalarui"
+)
+
+const usage = `Usage: scalarui <command> [flags]
+
+Commands:
+  export    write the docs as a static site
+
+Run "scalarui <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(exitCode(run(os.Args[1:], os.Stderr), os.Stderr))
+}
+
+// exitCode reports err on stderr and returns the process exit status
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+	if !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stderr, err)
+	}
+	return 2
+}
+
+// run executes the command named by args[0]
+func run(args []string, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+	switch args[0] {
+	case "export":
+		return export(args[1:], stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+	return fmt.Errorf("scalarui: unknown command %q\n\n%s", args[0], usage)
+}
+
+// export writes a static site from a spec and a config file
+func export(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		specPath   = flags.String("spec", "", "OpenAPI or Swagger 2.0 document; $refs to other files are bundled")
+		configPath = flags.String("config", "", "YAML or JSON config file (same keys as LoadConfig)")
+		out        = flags.String("out", "site", "output directory")
+		favicon    = flags.String("favicon", "", "icon file to copy into the site")
+		bundle     = flags.String("bundle", "", "Scalar standalone.js to self-host instead of loading it from jsdelivr")
+		version    = flags.String("bundle-version", "", "Scalar version of the -bundle file")
+	)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, "Usage: scalarui export [flags]\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("scalarui export: unexpected argument %q", flags.Arg(0))
+	}
+
+	config, err := scalarui.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *specPath != "" {
+		dir, name := filepath.Split(*specPath)
+		if dir == "" {
+			dir = "."
+		}
+		if config, err = config.WithBundledSpec(os.DirFS(dir), name); err != nil {
+			return err
+		}
+	}
+	if config.Content == nil && config.URL == "" && len(config.Sources) == 0 {
+		return errors.New("scalarui export: no document; pass -spec or set content, url or sources in -config")
+	}
+	if *bundle != "" {
+		b, err := scalarui.LoadBundle(os.DirFS(filepath.Dir(*bundle)), filepath.Base(*bundle), *version)
+		if err != nil {
+			return err
+		}
+		config.WithBundle(b)
+	}
+
+	ui := scalarui.New(config)
+	for _, w := range ui.Warnings() {
+		fmt.Fprintln(stderr, "warning:", w)
+	}
+	return ui.Export(*out, scalarui.ExportOptions{Favicon: *favicon})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// command runs the command line and returns its exit status and stderr
+func command(args ...string) (int, string) {
+	var stderr bytes.Buffer
+	code := exitCode(run(args, &stderr), &stderr)
+	return code, stderr.String()
+}
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "openapi.yaml")
+	doc := "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: '1'\npaths: {}\n"
+	if err := os.WriteFile(spec, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bundle := filepath.Join(dir, "standalone.js")
+	if err := os.WriteFile(bundle, []byte("// scalar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "docs.yaml")
+	if err := os.WriteFile(config, []byte("title: Pet Store\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "site")
+	code, stderr := command("export", "-spec", spec, "-config", config, "-out", out,
+		"-bundle", bundle, "-bundle-version", "1.28.0")
+	if code != 0 {
+		t.Fatalf("exit %d:\n%s", code, stderr)
+	}
+
+	var files []string
+	filepath.WalkDir(out, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(out, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if got, want := strings.Join(files, " "), "index.html openapi.json openapi.yaml scalar.js"; got != want {
+		t.Errorf("wrote %s, want %s", got, want)
+	}
+
+	index, _ := os.ReadFile(filepath.Join(out, "index.html"))
+	for _, want := range []string{"<title>Pet Store</title>", `"url": "./openapi.yaml"`, `src="./scalar.js?v=`} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index.html does not contain %s", want)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "openapi.yaml")); string(data) != doc {
+		t.Errorf("openapi.yaml = %q, want the spec as written", data)
+	}
+}
+
+func TestExportFailures(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "site")
+
+	tests := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{"missing spec", []string{"export", "-spec", filepath.Join(dir, "missing.yaml"), "-out", out}, "missing.yaml"},
+		{"missing config", []string{"export", "-config", filepath.Join(dir, "missing.yaml"), "-out", out}, "load config"},
+		{"no document", []string{"export", "-out", out}, "no document"},
+		{"argument", []string{"export", "-out", out, "extra"}, `unexpected argument "extra"`},
+		{"flag", []string{"export", "-nope"}, "flag provided but not defined: -nope"},
+		{"command", []string{"serve"}, `unknown command "serve"`},
+		{"no command", nil, "Usage: scalarui <command>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stderr := command(tt.args...)
+			if code != 2 {
+				t.Errorf("exit %d, want 2", code)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("stderr does not mention %q:\n%s", tt.stderr, stderr)
+			}
+		})
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("failed exports wrote %s", out)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	code, stderr := command("export", "-h")
+	if code != 2 || !strings.Contains(stderr, "Usage: scalarui export") || !strings.Contains(stderr, "-bundle-version") {
+		t.Errorf("exit %d:\n%s", code, stderr)
+	}
+}
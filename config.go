@@ -1,5 +1,10 @@
 package scalarui
 
+import (
+	"io"
+	"io/fs"
+)
+
 // Config represents the complete Scalar Universal Configuration
 type Config struct {
 	/* ------------------------------------------------------------- */
@@ -7,9 +12,24 @@ type Config struct {
 	/* ------------------------------------------------------------- */
 
 	URL          string      `json:"url,omitempty"`          // URL to the OpenAPI/Swagger document
-	Content      interface{} `json:"content,omitempty"`      // Direct OpenAPI/Swagger content (YAML/JSON/string/object)
+	Content      interface{} `json:"content,omitempty"`      // Direct OpenAPI/Swagger content (*Spec/YAML/JSON/string/object)
 	ProxyURL     string      `json:"proxyUrl,omitempty"`     // CORS proxy URL used for fetching specs
 	HotReloadURL string      `json:"hotReloadUrl,omitempty"` // Hot-reload URL for development
+
+	/* ------------------------------------------------------------- */
+	/* Scalar Script (not sent to Scalar) */
+	/* ------------------------------------------------------------- */
+
+	ScalarVersion   string  `json:"-"` // Pinned CDN package version
+	ScriptURL       string  `json:"-"` // Override the Scalar script URL
+	ScriptIntegrity string  `json:"-"` // SRI hash for ScriptURL or the pinned CDN script
+	Bundle          *Bundle `json:"-"` // Self-hosted Scalar bundle served by Mount
+	Proxy           *Proxy  `json:"-"` // Same-origin proxy served by Mount at ProxyURL
+
+	Filter *SpecFilter `json:"-"` // Applied to Content and to Sources without their own
+
+	ContentSecurityPolicy bool `json:"-"` // Send a nonce-based CSP header from the handlers
+
 	/* ------------------------------------------------------------- */
 	/* Display Options */
 	/* ------------------------------------------------------------- */
@@ -24,51 +44,51 @@ type Config struct {
 
 	Theme              string            `json:"theme,omitempty"`              // Theme name (default, alternate, moon, purple, etc.)
 	Layout             string            `json:"layout,omitempty"`             // Layout type (modern, classic)
-	DarkMode           bool              `json:"darkMode,omitempty"`           // Enable dark mode
-	ForceDarkModeState string            `json:"forceDarkModeState,omitempty"` // Force dark/light/system mode
+	DarkMode           *bool             `json:"darkMode,omitempty"`           // Enable dark mode
+	ForceDarkModeState string            `json:"forceDarkModeState,omitempty"` // dark|light
 	CustomCSS          string            `json:"customCss,omitempty"`          // Custom CSS injected into UI
 	Variables          map[string]string `json:"variables,omitempty"`          // CSS custom properties
-	WithDefaultFonts   bool              `json:"withDefaultFonts,omitempty"`   // Load Scalar's default web fonts
+	WithDefaultFonts   *bool             `json:"withDefaultFonts,omitempty"`   // Load Scalar's default web fonts
 
 	/* ------------------------------------------------------------- */
 	/* Sidebar / Visibility Toggles */
 	/* ------------------------------------------------------------- */
 
-	ShowSidebar             bool   `json:"showSidebar,omitempty"`                  // Show/hide sidebar
-	HideMethods             bool   `json:"hideMethods,omitempty"`                  // Hide HTTP method badges
-	HideModels              bool   `json:"hideModels,omitempty"`                   // Hide models panel
-	HideSearch              bool   `json:"hideSearch,omitempty"`                   // Hide search bar
-	HideTestRequestButton   bool   `json:"hideTestRequestButton,omitempty"`        // Hide "Test Request" button
-	HideClientButton        bool   `json:"hideClientButton,omitempty"`             // Hide "Client SDK" button
-	HideDownloadButton      bool   `json:"hideDownloadButton,omitempty"`           // Hide "Download OpenAPI" button
-	HideDarkModeToggle      bool   `json:"hideDarkModeToggle,omitempty"`           // Hide dark mode toggle
+	ShowSidebar             *bool  `json:"showSidebar,omitempty"`                  // Show/hide sidebar
+	HideMethods             *bool  `json:"hideMethods,omitempty"`                  // Hide HTTP method badges
+	HideModels              *bool  `json:"hideModels,omitempty"`                   // Hide models panel
+	HideSearch              *bool  `json:"hideSearch,omitempty"`                   // Hide search bar
+	HideTestRequestButton   *bool  `json:"hideTestRequestButton,omitempty"`        // Hide "Test Request" button
+	HideClientButton        *bool  `json:"hideClientButton,omitempty"`             // Hide "Client SDK" button
+	HideDownloadButton      *bool  `json:"hideDownloadButton,omitempty"`           // Hide "Download OpenAPI" button
+	HideDarkModeToggle      *bool  `json:"hideDarkModeToggle,omitempty"`           // Hide dark mode toggle
 	DocumentDownloadType    string `json:"documentDownloadType,omitempty"`         // yaml|json|both|none|direct
-	OperationTitleSource    string `json:"operationTitleSource,omitempty"`         // summary|id
-	OrderRequiredFirst      bool   `json:"orderRequiredPropertiesFirst,omitempty"` // Required props first
-	OrderSchemaPropertiesBy string `json:"orderSchemaPropertiesBy,omitempty"`      // alpha|custom
+	OperationTitleSource    string `json:"operationTitleSource,omitempty"`         // summary|path
+	OrderRequiredFirst      *bool  `json:"orderRequiredPropertiesFirst,omitempty"` // Required props first
+	OrderSchemaPropertiesBy string `json:"orderSchemaPropertiesBy,omitempty"`      // alpha|preserve
 
 	/* ------------------------------------------------------------- */
 	/* Auto Expansion */
 	/* ------------------------------------------------------------- */
 
-	ExpandAllResponses     bool `json:"expandAllResponses,omitempty"`     // Expand all responses
-	ExpandAllModelSections bool `json:"expandAllModelSections,omitempty"` // Expand all models
-	DefaultOpenAllTags     bool `json:"defaultOpenAllTags,omitempty"`     // Expand all tag groups
+	ExpandAllResponses     *bool `json:"expandAllResponses,omitempty"`     // Expand all responses
+	ExpandAllModelSections *bool `json:"expandAllModelSections,omitempty"` // Expand all models
+	DefaultOpenAllTags     *bool `json:"defaultOpenAllTags,omitempty"`     // Expand all tag groups
 
 	/* ------------------------------------------------------------- */
 	/* Developer Tools */
 	/* ------------------------------------------------------------- */
 
 	ShowDeveloperTools string `json:"showDeveloperTools,omitempty"` // always|never|localhost
-	Interactive        bool   `json:"interactive,omitempty"`        // Enable "Try It" features
+	Interactive        *bool  `json:"interactive,omitempty"`        // Enable "Try It" features
 
 	/* ------------------------------------------------------------- */
 	/* Authentication */
 	/* ------------------------------------------------------------- */
 
-	PersistAuth     bool                   `json:"persistAuth,omitempty"`     // Persist auth information
+	PersistAuth     *bool                  `json:"persistAuth,omitempty"`     // Persist auth information
 	Authentication  map[string]interface{} `json:"authentication,omitempty"`  // Auth schemes
-	WithCredentials bool                   `json:"withCredentials,omitempty"` // Send cookies on requests
+	WithCredentials *bool                  `json:"withCredentials,omitempty"` // Send cookies on requests
 
 	/* ------------------------------------------------------------- */
 	/* Servers / Path Routing */
@@ -101,11 +121,11 @@ type Config struct {
 	/* Telemetry / Loading State */
 	/* ------------------------------------------------------------- */
 
-	Telemetry bool `json:"telemetry,omitempty"` // Anonymous usage telemetry
-	IsLoading bool `json:"isLoading,omitempty"` // Force UI into loading state
+	Telemetry *bool `json:"telemetry,omitempty"` // Anonymous usage telemetry
+	IsLoading *bool `json:"isLoading,omitempty"` // Force UI into loading state
 
 	/* ------------------------------------------------------------- */
-	/* Callback Hooks (JS functions encoded as interface{}) */
+	/* Callback Hooks (JSFunc for raw JavaScript) */
 	/* ------------------------------------------------------------- */
 
 	OnSpecUpdate     interface{} `json:"onSpecUpdate,omitempty"`     // When spec updates
@@ -118,7 +138,7 @@ type Config struct {
 	OnSidebarClick   interface{} `json:"onSidebarClick,omitempty"`   // Sidebar interaction
 
 	/* ------------------------------------------------------------- */
-	/* Slug Generators */
+	/* Slug Generators (JSFunc for raw JavaScript) */
 	/* ------------------------------------------------------------- */
 
 	GenerateHeadingSlug   interface{} `json:"generateHeadingSlug,omitempty"`   // Custom slug function
@@ -128,7 +148,7 @@ type Config struct {
 	GenerateWebhookSlug   interface{} `json:"generateWebhookSlug,omitempty"`   // Custom webhook slug
 
 	/* ------------------------------------------------------------- */
-	/* Sorting Options */
+	/* Sorting Options (name or JSFunc) */
 	/* ------------------------------------------------------------- */
 
 	TagsSorter       interface{} `json:"tagsSorter,omitempty"`       // Sort tag groups
@@ -167,6 +187,8 @@ type SourceConfig struct {
 	URL     string      `json:"url,omitempty"`     // URL to spec
 	Content interface{} `json:"content,omitempty"` // Spec inline
 	Default bool        `json:"default,omitempty"` // Default selected doc
+
+	Filter *SpecFilter `json:"-"` // Overrides Config.Filter for this document
 }
 
 /* ------------------------------------------------------------- */
@@ -179,14 +201,21 @@ type Plugin struct {
 	Enabled bool                   `json:"enabled,omitempty"` // Enabled state
 }
 
+// Bool returns a pointer to v, for setting the tri-state boolean fields.
+// A nil field is left out of the JSON so Scalar applies its own default;
+// a false one is sent and turns the feature off.
+func Bool(v bool) *bool {
+	return &v
+}
+
 // NewConfig creates a new config with sensible defaults
 func NewConfig() *Config {
 	return &Config{
 		Theme:              "default",
 		Layout:             "modern",
-		ShowSidebar:        true,
+		ShowSidebar:        Bool(true),
 		ShowDeveloperTools: "always",
-		Interactive:        true,
+		Interactive:        Bool(true),
 		ProxyURL:           "https://proxy.scalar.com",
 		Variables:          make(map[string]string),
 		Authentication:     make(map[string]interface{}),
@@ -207,6 +236,74 @@ func (c *Config) WithContent(content string) *Config {
 	return c
 }
 
+// WithScalarVersion pins the Scalar CDN package version
+func (c *Config) WithScalarVersion(version string) *Config {
+	c.ScalarVersion = version
+	return c
+}
+
+// WithScriptURL loads the Scalar script from url, checked against integrity if set
+func (c *Config) WithScriptURL(url, integrity string) *Config {
+	c.ScriptURL = url
+	c.ScriptIntegrity = integrity
+	return c
+}
+
+// WithBundle serves a self-hosted Scalar bundle instead of the CDN
+func (c *Config) WithBundle(b *Bundle) *Config {
+	c.Bundle = b
+	return c
+}
+
+// WithLocalProxy serves a Proxy for allowedHosts at path and points ProxyURL
+// at it instead of proxy.scalar.com. path is the URL the browser requests,
+// so it must fall below the Mount prefix, e.g. "/docs/proxy".
+func (c *Config) WithLocalProxy(path string, allowedHosts ...string) *Config {
+	c.ProxyURL = path
+	c.Proxy = NewProxy(allowedHosts...)
+	return c
+}
+
+// WithContentSecurityPolicy makes the handlers send a per-request nonce and
+// a matching Content-Security-Policy header
+func (c *Config) WithContentSecurityPolicy(enabled bool) *Config {
+	c.ContentSecurityPolicy = enabled
+	return c
+}
+
+// WithSpec sets a loaded spec as the document content
+func (c *Config) WithSpec(spec *Spec) *Config {
+	c.Content = spec
+	return c
+}
+
+// WithSpecFile loads the document content from a JSON or YAML file
+func (c *Config) WithSpecFile(path string) (*Config, error) {
+	spec, err := LoadSpecFile(path)
+	if err != nil {
+		return c, err
+	}
+	return c.WithSpec(spec), nil
+}
+
+// WithSpecFS loads the document content from a file in fsys
+func (c *Config) WithSpecFS(fsys fs.FS, name string) (*Config, error) {
+	spec, err := LoadSpecFS(fsys, name)
+	if err != nil {
+		return c, err
+	}
+	return c.WithSpec(spec), nil
+}
+
+// WithSpecReader loads the document content from r
+func (c *Config) WithSpecReader(r io.Reader) (*Config, error) {
+	spec, err := LoadSpecReader(r)
+	if err != nil {
+		return c, err
+	}
+	return c.WithSpec(spec), nil
+}
+
 // WithTitle sets the page title
 func (c *Config) WithTitle(title string) *Config {
 	c.Title = title
@@ -227,7 +324,7 @@ func (c *Config) WithTheme(theme string) *Config {
 
 // WithDarkMode enables or disables dark mode
 func (c *Config) WithDarkMode(enabled bool) *Config {
-	c.DarkMode = enabled
+	c.DarkMode = Bool(enabled)
 	return c
 }
 
@@ -269,13 +366,13 @@ func (c *Config) WithAuthentication(auth map[string]interface{}) *Config {
 
 // WithInteractive enables or disables interactive mode
 func (c *Config) WithInteractive(interactive bool) *Config {
-	c.Interactive = interactive
+	c.Interactive = Bool(interactive)
 	return c
 }
 
 // WithSidebar shows or hides the sidebar
 func (c *Config) WithSidebar(show bool) *Config {
-	c.ShowSidebar = show
+	c.ShowSidebar = Bool(show)
 	return c
 }
 
@@ -291,19 +388,19 @@ func (c *Config) WithDeveloperTools(show bool) *Config {
 
 // HideHTTPMethods hides HTTP methods in sidebar
 func (c *Config) HideHTTPMethods() *Config {
-	c.HideMethods = true
+	c.HideMethods = Bool(true)
 	return c
 }
 
 // HideModelsSection hides the models section
 func (c *Config) HideModelsSection() *Config {
-	c.HideModels = true
+	c.HideModels = Bool(true)
 	return c
 }
 
 // HideDownload hides the download button
 func (c *Config) HideDownload() *Config {
-	c.HideDownloadButton = true
+	c.HideDownloadButton = Bool(true)
 	return c
 }
 
@@ -315,25 +412,25 @@ func (c *Config) WithForceDarkModeState(state string) *Config {
 
 // WithHideSearch hides the search bar
 func (c *Config) WithHideSearch(hide bool) *Config {
-	c.HideSearch = hide
+	c.HideSearch = Bool(hide)
 	return c
 }
 
 // WithHideTestRequestButton hides the "Test Request" button
 func (c *Config) WithHideTestRequestButton(hide bool) *Config {
-	c.HideTestRequestButton = hide
+	c.HideTestRequestButton = Bool(hide)
 	return c
 }
 
 // WithHideClientButton hides the Client SDK button
 func (c *Config) WithHideClientButton(hide bool) *Config {
-	c.HideClientButton = hide
+	c.HideClientButton = Bool(hide)
 	return c
 }
 
 // WithHideDarkModeToggle hides the dark mode toggle
 func (c *Config) WithHideDarkModeToggle(hide bool) *Config {
-	c.HideDarkModeToggle = hide
+	c.HideDarkModeToggle = Bool(hide)
 	return c
 }
 
@@ -343,7 +440,7 @@ func (c *Config) WithDocumentDownloadType(t string) *Config {
 	return c
 }
 
-// WithOperationTitleSource sets summary|id
+// WithOperationTitleSource sets summary|path
 func (c *Config) WithOperationTitleSource(src string) *Config {
 	c.OperationTitleSource = src
 	return c
@@ -351,11 +448,11 @@ func (c *Config) WithOperationTitleSource(src string) *Config {
 
 // WithOrderRequiredFirst enables required-properties-first ordering
 func (c *Config) WithOrderRequiredFirst(enabled bool) *Config {
-	c.OrderRequiredFirst = enabled
+	c.OrderRequiredFirst = Bool(enabled)
 	return c
 }
 
-// WithOrderSchemaPropertiesBy sets alpha|custom|preserve
+// WithOrderSchemaPropertiesBy sets alpha|preserve
 func (c *Config) WithOrderSchemaPropertiesBy(mode string) *Config {
 	c.OrderSchemaPropertiesBy = mode
 	return c
@@ -363,31 +460,31 @@ func (c *Config) WithOrderSchemaPropertiesBy(mode string) *Config {
 
 // WithExpandAllResponses expands all responses by default
 func (c *Config) WithExpandAllResponses(enabled bool) *Config {
-	c.ExpandAllResponses = enabled
+	c.ExpandAllResponses = Bool(enabled)
 	return c
 }
 
 // WithExpandAllModelSections expands all models
 func (c *Config) WithExpandAllModelSections(enabled bool) *Config {
-	c.ExpandAllModelSections = enabled
+	c.ExpandAllModelSections = Bool(enabled)
 	return c
 }
 
 // WithDefaultOpenAllTags expands all tag groups
 func (c *Config) WithDefaultOpenAllTags(enabled bool) *Config {
-	c.DefaultOpenAllTags = enabled
+	c.DefaultOpenAllTags = Bool(enabled)
 	return c
 }
 
 // WithPersistAuth enables localStorage auth persistence
 func (c *Config) WithPersistAuth(enabled bool) *Config {
-	c.PersistAuth = enabled
+	c.PersistAuth = Bool(enabled)
 	return c
 }
 
 // WithWithCredentials sets credentials=true for fetch
 func (c *Config) WithWithCredentials(enabled bool) *Config {
-	c.WithCredentials = enabled
+	c.WithCredentials = Bool(enabled)
 	return c
 }
 
@@ -429,13 +526,13 @@ func (c *Config) WithHiddenClients(h interface{}) *Config {
 
 // WithTelemetry enables/disables telemetry
 func (c *Config) WithTelemetry(enabled bool) *Config {
-	c.Telemetry = enabled
+	c.Telemetry = Bool(enabled)
 	return c
 }
 
 // WithIsLoading forces loading state
 func (c *Config) WithIsLoading(loading bool) *Config {
-	c.IsLoading = loading
+	c.IsLoading = Bool(loading)
 	return c
 }
 
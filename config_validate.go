@@ -0,0 +1,94 @@
+package scalarui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Accepted values of the enum-like string fields, keyed by JSON pointer
+var configEnums = []struct {
+	pointer string
+	value   func(*Config) string
+	allowed []string
+}{
+	{"/theme", func(c *Config) string { return c.Theme }, []string{
+		"alternate", "default", "moon", "purple", "solarized", "bluePlanet", "deepSpace",
+		"saturn", "kepler", "elysiajs", "fastify", "mars", "laserwave", "none",
+	}},
+	{"/layout", func(c *Config) string { return c.Layout }, []string{"modern", "classic"}},
+	{"/forceDarkModeState", func(c *Config) string { return c.ForceDarkModeState }, []string{"dark", "light"}},
+	{"/documentDownloadType", func(c *Config) string { return c.DocumentDownloadType }, []string{"yaml", "json", "both", "none", "direct"}},
+	{"/operationTitleSource", func(c *Config) string { return c.OperationTitleSource }, []string{"summary", "path"}},
+	{"/orderSchemaPropertiesBy", func(c *Config) string { return c.OrderSchemaPropertiesBy }, []string{"alpha", "preserve"}},
+	{"/showDeveloperTools", func(c *Config) string { return c.ShowDeveloperTools }, []string{"always", "never", "localhost"}},
+	{"/tagsSorter", func(c *Config) string { s, _ := c.TagsSorter.(string); return s }, []string{"alpha"}},
+	{"/operationsSorter", func(c *Config) string { s, _ := c.OperationsSorter.(string); return s }, []string{"alpha", "method"}},
+}
+
+// Validate checks enum-like fields and contradictory options. It returns
+// ValidationErrors listing every problem, with JSON pointers using the
+// config's JSON keys, or nil.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	addf := func(ptr, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, enum := range configEnums {
+		if v := enum.value(c); v != "" && !contains(enum.allowed, v) {
+			addf(enum.pointer, "invalid value %q (want %s)", v, strings.Join(enum.allowed, "|"))
+		}
+	}
+
+	if c.URL != "" && c.Content != nil {
+		addf("/content", "url and content are both set; Scalar only uses one")
+	}
+	if len(c.Sources) > 0 && (c.URL != "" || c.Content != nil) {
+		addf("/sources", "sources cannot be mixed with top-level url or content")
+	}
+
+	var defaults []string
+	slugs := map[string]int{}
+	for i, src := range c.Sources {
+		ptr := fmt.Sprintf("/sources/%d", i)
+		switch {
+		case src.URL == "" && src.Content == nil:
+			addf(ptr, "needs a url or content")
+		case src.URL != "" && src.Content != nil:
+			addf(ptr+"/content", "url and content are both set")
+		}
+		if src.Slug != "" {
+			if first, dup := slugs[src.Slug]; dup {
+				addf(ptr+"/slug", "duplicate slug %q (also used by /sources/%d)", src.Slug, first)
+			} else {
+				slugs[src.Slug] = i
+			}
+		}
+		if src.Default {
+			defaults = append(defaults, ptr)
+		}
+	}
+	if len(defaults) > 1 {
+		addf("/sources", "more than one default source: %s", strings.Join(defaults, ", "))
+	}
+
+	for i, server := range c.Servers {
+		if server.URL == "" {
+			addf(fmt.Sprintf("/servers/%d/url", i), "is required")
+		}
+	}
+
+	if c.ScriptURL != "" && c.Bundle != nil {
+		addf("", "ScriptURL and Bundle are both set; the bundle is never served")
+	}
+	if c.Proxy != nil {
+		if !strings.HasPrefix(c.ProxyURL, "/") {
+			addf("/proxyUrl", "must be a path served by Mount when a local proxy is set")
+		}
+		if len(c.Proxy.AllowedHosts) == 0 {
+			addf("/proxyUrl", "local proxy allows no upstream hosts")
+		}
+	}
+
+	return errs.orNil()
+}
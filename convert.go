@@ -0,0 +1,427 @@
+package scalarui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonNumberPattern matches numbers written the way JSON allows
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?([eE][-+]?\d+)?$`)
+
+// Encode returns the document in format: Raw when it is already in that
+// format, otherwise a conversion that keeps key order and every value
+func (s *Spec) Encode(format SpecFormat) ([]byte, error) {
+	if format == s.Format {
+		return s.Raw, nil
+	}
+	switch format {
+	case FormatJSON:
+		return YAMLToJSON(s.Raw)
+	case FormatYAML:
+		return JSONToYAML(s.Raw)
+	}
+	return nil, fmt.Errorf("scalarui: unknown spec format %q", format)
+}
+
+// YAMLToJSON converts a YAML document to indented JSON. Mapping keys keep
+// their order, aliases and merge keys are expanded, and timestamps stay as
+// written. Values JSON cannot hold (.inf, .nan) are an error.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("scalarui: invalid YAML: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, &node); err != nil {
+		return nil, fmt.Errorf("scalarui: convert to JSON: %w", err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("scalarui: convert to JSON: %w", err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// JSONToYAML converts a JSON document to YAML. Object keys keep their order,
+// numbers keep their exact text, and strings that would read as another
+// type are quoted.
+func JSONToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := yamlNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: invalid JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("scalarui: convert to YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("scalarui: convert to YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+/* ------------------------------------------------------------- */
+/* YAML to JSON */
+/* ------------------------------------------------------------- */
+
+// yamlPair is one entry of a mapping after merge keys are expanded
+type yamlPair struct {
+	key   string
+	value *yaml.Node
+}
+
+// writeJSON writes n to buf as compact JSON
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, n.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i, pair := range mappingPairs(n) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(jsonString(pair.key))
+			buf.WriteByte(':')
+			if err := writeJSON(buf, pair.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(b))
+	case "!!int", "!!float":
+		num, err := jsonNumber(n)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(num)
+	default:
+		buf.WriteString(jsonString(n.Value))
+	}
+	return nil
+}
+
+// mappingPairs returns the entries of a mapping in order. Merged entries
+// take the place of their "<<" key unless the mapping sets them itself.
+func mappingPairs(n *yaml.Node) []yamlPair {
+	explicit := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].ShortTag() != "!!merge" {
+			explicit[yamlKey(n.Content[i])] = true
+		}
+	}
+
+	var pairs []yamlPair
+	seen := map[string]bool{}
+	add := func(key string, value *yaml.Node) {
+		if !seen[key] {
+			seen[key] = true
+			pairs = append(pairs, yamlPair{key, value})
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			add(yamlKey(key), value)
+			continue
+		}
+		for _, merged := range mergeSources(value) {
+			for _, pair := range mappingPairs(merged) {
+				if !explicit[pair.key] {
+					add(pair.key, pair.value)
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// mergeSources returns the mappings a merge key value refers to
+func mergeSources(n *yaml.Node) []*yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		return []*yaml.Node{n}
+	case yaml.SequenceNode:
+		var out []*yaml.Node
+		for _, item := range n.Content {
+			out = append(out, mergeSources(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// yamlKey returns a mapping key as written
+func yamlKey(n *yaml.Node) string {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n.Value
+}
+
+// jsonNumber returns the JSON text of a YAML number, keeping the text as
+// written when JSON accepts it
+func jsonNumber(n *yaml.Node) (string, error) {
+	if jsonNumberPattern.MatchString(n.Value) {
+		return n.Value, nil
+	}
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return "", err
+	}
+	switch num := v.(type) {
+	case int:
+		return strconv.Itoa(num), nil
+	case int64:
+		return strconv.FormatInt(num, 10), nil
+	case uint64:
+		return strconv.FormatUint(num, 10), nil
+	case float64:
+		if math.IsInf(num, 0) || math.IsNaN(num) {
+			return "", fmt.Errorf("line %d: %s has no JSON equivalent", n.Line, n.Value)
+		}
+		return strconv.FormatFloat(num, 'g', -1, 64), nil
+	}
+	return jsonString(n.Value), nil
+}
+
+// jsonString quotes s without escaping HTML characters
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+/* ------------------------------------------------------------- */
+/* JSON to YAML */
+/* ------------------------------------------------------------- */
+
+// yamlNode reads the next JSON value from dec as a YAML node
+func yamlNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if v == '{' {
+			n = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if n.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			value, err := yamlNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, value)
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+/* ------------------------------------------------------------- */
+/* Derived Documents */
+/* ------------------------------------------------------------- */
+
+// wellKnownKeys orders keys a derived document adds: OpenAPI fields in their
+// conventional order, ahead of any other key
+var wellKnownKeys = []string{
+	"openapi", "swagger", "info", "jsonSchemaDialect", "servers", "host", "basePath", "schemes",
+	"consumes", "produces", "security", "tags", "paths", "webhooks", "components",
+	"definitions", "securityDefinitions", "externalDocs",
+	"name", "in", "title", "summary", "description", "operationId", "version", "url",
+	"required", "type", "format", "items", "properties", "parameters", "requestBody",
+	"content", "schema", "responses",
+}
+
+// orderedJSON marshals doc as indented JSON. Object keys keep their order in
+// orig, the node doc was derived from; keys orig lacks are placed by
+// wellKnownKeys, others sorted at the end.
+func orderedJSON(doc interface{}, orig *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeOrderedJSON(&buf, doc, orig); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// writeOrderedJSON writes v to buf as compact JSON; orig may be nil
+func writeOrderedJSON(buf *bytes.Buffer, v interface{}, orig *yaml.Node) error {
+	for orig != nil && (orig.Kind == yaml.AliasNode || orig.Kind == yaml.DocumentNode) {
+		if orig.Kind == yaml.AliasNode {
+			orig = orig.Alias
+		} else if len(orig.Content) > 0 {
+			orig = orig.Content[0]
+		} else {
+			orig = nil
+		}
+	}
+
+	switch node := v.(type) {
+	case map[string]interface{}:
+		var origKeys []string
+		children := map[string]*yaml.Node{}
+		if orig != nil && orig.Kind == yaml.MappingNode {
+			for _, pair := range mappingPairs(orig) {
+				origKeys = append(origKeys, pair.key)
+				children[pair.key] = pair.value
+			}
+		}
+
+		keys := make([]string, 0, len(node))
+		for _, key := range origKeys {
+			if _, ok := node[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		var added []string
+		for key := range node {
+			if _, ok := children[key]; !ok {
+				added = append(added, key)
+			}
+		}
+		sort.Strings(added)
+		for _, key := range added {
+			keys = insertKey(keys, key)
+		}
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(jsonString(key))
+			buf.WriteByte(':')
+			if err := writeOrderedJSON(buf, node[key], children[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range node {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			var child *yaml.Node
+			if orig != nil && orig.Kind == yaml.SequenceNode && i < len(orig.Content) {
+				child = orig.Content[i]
+			}
+			if err := writeOrderedJSON(buf, item, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case string:
+		buf.WriteString(jsonString(node))
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// insertKey adds key to keys ahead of the first well-known key that
+// conventionally follows it, or at the end
+func insertKey(keys []string, key string) []string {
+	rank := keyRank(key)
+	for i, existing := range keys {
+		if r := keyRank(existing); r < len(wellKnownKeys) && r > rank {
+			return append(keys[:i], append([]string{key}, keys[i:]...)...)
+		}
+	}
+	return append(keys, key)
+}
+
+// keyRank returns the position of key in wellKnownKeys, or the list length
+func keyRank(key string) int {
+	for i, known := range wellKnownKeys {
+		if key == known {
+			return i
+		}
+	}
+	return len(wellKnownKeys)
+}
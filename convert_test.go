@@ -0,0 +1,153 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	in := `openapi: 3.1.0
+info:
+  version: "1.0"
+  title: Orders
+x-defaults: &defaults
+  limit: 10
+  offset: 0
+x-query:
+  <<: *defaults
+  limit: 50
+x-values: [010, 0x1F, 1.50, 1e3, true, "true", null, 2024-01-02, "<b>"]
+`
+	out, err := YAMLToJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("YAMLToJSON: %v", err)
+	}
+	compact := strings.Join(strings.Fields(string(out)), "")
+	want := `{"openapi":"3.1.0","info":{"version":"1.0","title":"Orders"},` +
+		`"x-defaults":{"limit":10,"offset":0},"x-query":{"offset":0,"limit":50},` +
+		`"x-values":[8,31,1.50,1e3,true,"true",null,"2024-01-02","<b>"]}`
+	if compact != want {
+		t.Errorf("got  %s\nwant %s", compact, want)
+	}
+
+	if _, err := YAMLToJSON([]byte("x: .inf\n")); err == nil {
+		t.Errorf("YAMLToJSON accepted .inf")
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	in := `{"openapi": "3.1.0", "info": {"version": "1.0", "title": "Orders"},
+		"x-values": ["true", "123", "null", "<<", 12345678901234567890, 1.50, false, null, "a\nb"]}`
+	out, err := JSONToYAML([]byte(in))
+	if err != nil {
+		t.Fatalf("JSONToYAML: %v", err)
+	}
+	back, err := YAMLToJSON(out)
+	if err != nil {
+		t.Fatalf("YAMLToJSON(%s): %v", out, err)
+	}
+	compact := strings.Join(strings.Fields(string(back)), "")
+	want := `{"openapi":"3.1.0","info":{"version":"1.0","title":"Orders"},` +
+		`"x-values":["true","123","null","<<",12345678901234567890,1.50,false,null,"a\nb"]}`
+	if compact != want {
+		t.Errorf("round trip changed the document:\n got  %s\nwant %s\nyaml:\n%s", compact, want, out)
+	}
+}
+
+func TestSpecEncodeRoundTrip(t *testing.T) {
+	for _, path := range []string{"demo/data/openapi.yaml", "testdata/specs/petstore-3.1.json"} {
+		spec, err := LoadSpecFile(path)
+		if err != nil {
+			t.Fatalf("LoadSpecFile: %v", err)
+		}
+		for _, format := range []SpecFormat{FormatJSON, FormatYAML} {
+			data, err := spec.Encode(format)
+			if err != nil {
+				t.Fatalf("%s: Encode(%s): %v", path, format, err)
+			}
+			converted, err := ParseSpec(data)
+			if err != nil {
+				t.Fatalf("%s: ParseSpec(%s): %v", path, format, err)
+			}
+			if converted.Format != format || !reflect.DeepEqual(converted.Doc, spec.Doc) {
+				t.Errorf("%s: %s encoding does not decode to the original document", path, format)
+			}
+		}
+	}
+}
+
+func TestMountServesBothFormats(t *testing.T) {
+	data, err := os.ReadFile("demo/data/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := NewConfig().WithContent(string(data))
+	config.DocumentDownloadType = "both"
+	handler := New(config).Mount("/docs")
+
+	for path, contentType := range map[string]string{
+		"/docs/openapi.yaml": "application/x-yaml; charset=utf-8",
+		"/docs/openapi.json": "application/json; charset=utf-8",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != contentType {
+			t.Errorf("GET %s = %d %s", path, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if page := rec.Body.String(); !strings.Contains(page, `"url": "/docs/openapi.yaml"`) || strings.Contains(page, `"content"`) {
+		t.Errorf("page does not link the served document")
+	}
+}
+
+func TestDerivedSpecsKeepKeyOrder(t *testing.T) {
+	swagger, err := LoadSpecFile("testdata/specs/petstore-swagger.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	upgraded, err := swagger.Upgrade()
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	filtered, err := upgraded.Filter(SpecFilter{ExcludePaths: []string{"/pets/{petId}/photo"}})
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	bundled, err := BundleSpec(os.DirFS("testdata/bundle"), "openapi.yaml")
+	if err != nil {
+		t.Fatalf("BundleSpec: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		spec *Spec
+		keys []string // Top-level keys in order
+	}{
+		{"upgrade", upgraded, []string{"openapi", "info", "x-logo", "servers", "security", "paths", "components"}},
+		{"filter", filtered, []string{"openapi", "info", "x-logo", "servers", "security", "paths", "components"}},
+		{"bundle", bundled, []string{"openapi", "info", "paths", "components"}},
+	}
+	for _, tt := range tests {
+		var keys []string
+		for _, line := range strings.Split(string(tt.spec.Raw), "\n") {
+			if key, _, ok := strings.Cut(line, ":"); ok && line != "" && line[0] != ' ' && line[0] != '-' {
+				keys = append(keys, key)
+			}
+		}
+		if !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%s: keys = %q, want %q", tt.name, keys, tt.keys)
+		}
+	}
+
+	// Paths keep the order they were written in
+	if i, j := strings.Index(string(filtered.Raw), "/pets:"), strings.Index(string(filtered.Raw), "/pets/{petId}:"); i < 0 || j < i {
+		t.Errorf("paths were reordered:\n%s", filtered.Raw)
+	}
+}
@@ -0,0 +1,140 @@
+package scalarui
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// scalarFontsOrigin serves the web fonts Scalar loads by default
+const scalarFontsOrigin = "https://fonts.scalar.com"
+
+// newNonce returns a random base64 nonce for a single response
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// contentSecurityPolicy builds a policy that allows the nonce-stamped inline
+// scripts plus exactly the origins the rendered page talks to, including the
+// specServers of the inline documents. Scalar inserts <style> elements and
+// style attributes at runtime without a nonce, so inline styles are allowed;
+// a nonce in style-src would disable that.
+func contentSecurityPolicy(config *Config, specServers []string, nonce, scriptURL string) string {
+	n := "'nonce-" + nonce + "'"
+
+	connect := origins(config.URL, config.ProxyURL, config.HotReloadURL, config.BaseServerURL)
+	connect = append(connect, specServers...)
+	for _, server := range config.Servers {
+		connect = append(connect, origins(server.URL)...)
+	}
+	for _, source := range config.Sources {
+		connect = append(connect, origins(source.URL)...)
+	}
+
+	var fonts []string
+	if config.WithDefaultFonts == nil || *config.WithDefaultFonts {
+		fonts = []string{scalarFontsOrigin}
+	}
+
+	directives := [][]string{
+		{"default-src", "'self'"},
+		append([]string{"script-src", "'self'", n}, origins(scriptURL)...),
+		append([]string{"style-src", "'self'", "'unsafe-inline'"}, fonts...),
+		append([]string{"font-src", "'self'", "data:"}, fonts...),
+		append([]string{"img-src", "'self'", "data:"}, origins(config.Favicon)...),
+		append([]string{"connect-src", "'self'"}, dedupe(connect)...),
+		{"object-src", "'none'"},
+		{"base-uri", "'self'"},
+	}
+
+	parts := make([]string, len(directives))
+	for i, d := range directives {
+		parts[i] = strings.Join(d, " ")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// origins returns the scheme://host of every absolute http(s) URL.
+// Relative URLs are covered by 'self'.
+func origins(urls ...string) []string {
+	var out []string
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		out = append(out, u.Scheme+"://"+u.Host)
+	}
+	return out
+}
+
+// serverOrigins returns the origins of the servers an inline document
+// declares, at the top level, on path items and on operations. Try-It
+// sends requests there unless a proxy is configured.
+func serverOrigins(content interface{}) []string {
+	spec, err := specFromContent(content)
+	if err != nil || spec == nil {
+		return nil
+	}
+	out := serverListOrigins(spec.Doc["servers"])
+	paths, _ := spec.Doc["paths"].(map[string]interface{})
+	for _, item := range paths {
+		item, _ := item.(map[string]interface{})
+		out = append(out, serverListOrigins(item["servers"])...)
+		for _, method := range httpMethods {
+			if op, ok := item[method].(map[string]interface{}); ok {
+				out = append(out, serverListOrigins(op["servers"])...)
+			}
+		}
+	}
+	return out
+}
+
+// serverListOrigins returns the origins of a servers list, expanding
+// variables to their default and enum values
+func serverListOrigins(servers interface{}) []string {
+	list, _ := servers.([]interface{})
+	var out []string
+	for _, s := range list {
+		server, _ := s.(map[string]interface{})
+		raw, _ := server["url"].(string)
+		urls := []string{raw}
+		variables, _ := server["variables"].(map[string]interface{})
+		for _, name := range sortedKeys(variables) {
+			variable, _ := variables[name].(map[string]interface{})
+			values := stringList(variable["enum"])
+			if def, ok := variable["default"].(string); ok {
+				values = append(values, def)
+			}
+			var expanded []string
+			for _, u := range urls {
+				for _, value := range values {
+					expanded = append(expanded, strings.ReplaceAll(u, "{"+name+"}", value))
+				}
+			}
+			if expanded != nil {
+				urls = expanded
+			}
+		}
+		out = append(out, origins(urls...)...)
+	}
+	return out
+}
+
+// dedupe sorts values and drops duplicates
+func dedupe(values []string) []string {
+	sort.Strings(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
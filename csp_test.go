@@ -0,0 +1,117 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestContentSecurityPolicyHeader(t *testing.T) {
+	config := NewConfig().
+		WithContentSecurityPolicy(true).
+		WithCustomCSS("a{}").
+		WithURL("https://api.example.com/openapi.json").
+		WithServer("https://api.example.com/v1", "Production")
+	config.HotReloadURL = "/docs/hot-reload"
+	handler := New(config).Mount("/docs")
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		return rec
+	}
+	rec := get()
+
+	policy := rec.Header().Get("Content-Security-Policy")
+	directives := map[string]string{}
+	for _, d := range strings.Split(policy, "; ") {
+		name, value, _ := strings.Cut(d, " ")
+		directives[name] = value
+	}
+	m := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(directives["script-src"])
+	if m == nil {
+		t.Fatalf("script-src has no nonce: %s", policy)
+	}
+	nonce := m[1]
+
+	if !strings.Contains(directives["style-src"], "'unsafe-inline'") || strings.Contains(directives["style-src"], "nonce") {
+		t.Errorf("style-src blocks Scalar's runtime styles: %s", directives["style-src"])
+	}
+	if !strings.Contains(directives["script-src"], "https://cdn.jsdelivr.net") {
+		t.Errorf("script-src does not allow the Scalar CDN: %s", directives["script-src"])
+	}
+	if directives["connect-src"] != "'self' https://api.example.com https://proxy.scalar.com" {
+		t.Errorf("connect-src = %q", directives["connect-src"])
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", cc)
+	}
+
+	tags := regexp.MustCompile(`<(script|style)[^>]*>`).FindAllString(rec.Body.String(), -1)
+	if len(tags) < 4 {
+		t.Fatalf("found only %d inline tags:\n%s", len(tags), rec.Body.String())
+	}
+	for _, tag := range tags {
+		if !strings.Contains(tag, `nonce="`+nonce+`"`) {
+			t.Errorf("%s does not carry the header's nonce %s", tag, nonce)
+		}
+	}
+
+	if next := get().Header().Get("Content-Security-Policy"); strings.Contains(next, nonce) {
+		t.Errorf("nonce was reused across responses")
+	}
+}
+
+func TestNonceOnlyFillsNonceAttributes(t *testing.T) {
+	const placeholder = "__scalarui_nonce__"
+	config := NewConfig().
+		WithContentSecurityPolicy(true).
+		WithTitle("x " + placeholder + " y").
+		WithContent(`{"openapi": "3.0.3", "info": {"title": "API", "version": "1", "description": "<script nonce=\"` + placeholder + `\">"}}`)
+	ui := New(config)
+
+	for i := 0; i < 2; i++ { // rendered, then cached
+		rec := httptest.NewRecorder()
+		ui.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		body := rec.Body.String()
+		nonce := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(rec.Header().Get("Content-Security-Policy"))[1]
+
+		if !strings.Contains(body, "<title>x "+placeholder+" y</title>") {
+			t.Errorf("title lost the placeholder text:\n%s", body)
+		}
+		if !strings.Contains(body, `\u003cscript nonce=\\\"`+placeholder+`\\\"\u003e`) {
+			t.Errorf("content lost the placeholder text:\n%s", body)
+		}
+		if all, attrs := strings.Count(body, nonce), strings.Count(body, `nonce="`+nonce+`"`); all != attrs {
+			t.Errorf("nonce appears %d times outside nonce attributes", all-attrs)
+		}
+	}
+}
+
+func TestContentSecurityPolicyAllowsSpecServers(t *testing.T) {
+	spec := `{"openapi": "3.0.3", "info": {"title": "API", "version": "1"},
+		"servers": [{"url": "https://api.example.com/v1"}, {"url": "/relative"}],
+		"paths": {"/users": {
+			"servers": [{"url": "https://users.example.com"}],
+			"get": {
+				"servers": [{"url": "https://{region}.example.com:{port}", "variables": {
+					"region": {"default": "eu", "enum": ["eu", "us"]},
+					"port": {"default": "8443"}
+				}}],
+				"responses": {"200": {"description": "ok"}}
+			}
+		}}}`
+	config := NewConfig().WithContentSecurityPolicy(true).WithContent(spec)
+	config.ProxyURL = ""
+	ui := New(config)
+
+	rec := httptest.NewRecorder()
+	ui.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	policy := rec.Header().Get("Content-Security-Policy")
+	want := "connect-src 'self' https://api.example.com https://eu.example.com:8443 https://us.example.com:8443 https://users.example.com;"
+	if !strings.Contains(policy, want) {
+		t.Errorf("policy = %s\nwant %s", policy, want)
+	}
+}
@@ -1,32 +1,33 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"net/http"
 	"path/filepath"
-	"time"
 
 	"github.com/nyxstack/scalarui"
 )
 
-var ui *scalarui.ScalarUI
-
 func main() {
 
-	config := scalarui.NewConfig()
-	config.URL = "http://localhost:8080/openapi.yaml"
-	config.HotReloadURL = "http://localhost:8080/hot-reload"
-	ui = scalarui.New(config)
+	specPath := filepath.Join("demo/data", "openapi.yaml")
+
+	config, err := scalarui.NewConfig().WithSpecFile(specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ui := scalarui.New(config)
+
+	// Reload open pages when the spec is edited
+	go ui.Watch(context.Background(), scalarui.NewFileWatcher(specPath))
+
+	// Page at "/", spec at "/openapi.yaml", hot reload at "/hot-reload"
+	http.Handle("/", ui.Mount("/"))
 
 	// cors on all routes
 	handler := withCORS(http.DefaultServeMux)
 
-	// Setup routes
-	http.HandleFunc("/", RenderScalarUi)
-	http.HandleFunc("/hot-reload", hotReloadHandler)
-	http.HandleFunc("/openapi.yaml", yamlHandler)
-
 	log.Fatal(http.ListenAndServe(":8080", handler))
 }
 
@@ -46,31 +47,3 @@ func withCORS(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
-
-var startTime = time.Now()
-
-func hotReloadHandler(w http.ResponseWriter, r *http.Request) {
-	// send a simple timestamp string
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.Header().Set("Cache-Control", "no-cache")
-
-	fmt.Fprintf(w, "%d", startTime.Unix())
-}
-
-// RenderScalarUi serves a simple hello world message
-func RenderScalarUi(w http.ResponseWriter, r *http.Request) {
-
-	str, err := ui.Render()
-	if err != nil {
-		http.Error(w, "Error rendering UI", http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, str)
-}
-
-// yamlHandler serves YAML data
-func yamlHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/x-yaml; charset=utf-8")
-	http.ServeFile(w, r, filepath.Join("demo/data", "openapi.yaml"))
-}
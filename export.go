@@ -0,0 +1,135 @@
+package scalarui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExportOptions holds settings for a static export
+type ExportOptions struct {
+	Favicon string // Local icon file copied into the site and used as its favicon
+}
+
+// Export writes the docs as a static site to dir, laid out like Mount:
+//
+//	index.html                -> HTML page
+//	openapi.json, .yaml       -> Config.Content in both formats
+//	scalar.js                 -> self-hosted Scalar bundle (Config.Bundle)
+//	favicon.<ext>             -> ExportOptions.Favicon
+//	<slug>/index.html         -> page showing only the source with that slug
+//	<slug>/openapi.json, .yaml -> that source's document
+//
+// Every link is relative, so the site can be published below any path.
+// Hot reload is left out, and a local Try-It proxy cannot be exported.
+func (s *ScalarUI) Export(dir string, opts ExportOptions) error {
+	snap := s.current.Load()
+	if snap.config.Proxy != nil {
+		return errors.New("scalarui: export: Config.Proxy needs a server; point ProxyURL at a hosted proxy instead")
+	}
+
+	for _, src := range snap.config.Sources {
+		if src.Slug == "." || src.Slug == ".." || strings.ContainsAny(src.Slug, `/\`) {
+			return fmt.Errorf("scalarui: export: slug %q is not a directory name", src.Slug)
+		}
+	}
+
+	config := snap.served.Clone()
+	config.HotReloadURL = ""
+
+	e := &exporter{dir: dir}
+	if opts.Favicon != "" {
+		data, err := os.ReadFile(opts.Favicon)
+		if err != nil {
+			return fmt.Errorf("scalarui: export: %w", err)
+		}
+		config.Favicon = "favicon" + filepath.Ext(opts.Favicon)
+		e.write(config.Favicon, data)
+	}
+	if config.Bundle != nil {
+		e.write(strings.TrimPrefix(BundlePath, "/"), config.Bundle.Bytes())
+	}
+
+	e.documents(snap, "")
+	e.page("index.html", config, ".", true)
+
+	for _, src := range config.Sources {
+		if src.Slug == "" {
+			continue
+		}
+		e.documents(snap, src.Slug)
+
+		// The slug page shows the one document, linked next to it
+		single := config.Clone()
+		single.Sources = nil
+		single.Content = nil
+		single.URL = src.URL
+		if format := contentFormat(src.Content); format != "" {
+			single.URL = "." + documentPath("", "", format)
+		}
+		if src.Title != "" {
+			single.Title = src.Title
+		}
+		if opts.Favicon != "" {
+			single.Favicon = "../" + single.Favicon
+		}
+		e.page(path.Join(src.Slug, "index.html"), single, "..", false)
+	}
+	return e.err
+}
+
+// exporter writes site files, keeping the first error
+type exporter struct {
+	dir string
+	err error
+}
+
+// write stores data at the slash-separated name below dir
+func (e *exporter) write(name string, data []byte) {
+	if e.err != nil {
+		return
+	}
+	file := filepath.Join(e.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		e.err = fmt.Errorf("scalarui: export: %w", err)
+		return
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		e.err = fmt.Errorf("scalarui: export: %w", err)
+	}
+}
+
+// documents writes the inline document at slug in both formats, if there
+// is one
+func (e *exporter) documents(snap *snapshot, slug string) {
+	for _, format := range []SpecFormat{FormatJSON, FormatYAML} {
+		name := strings.TrimPrefix(documentPath("", slug, format), "/")
+		data, err := snap.document(slug, format)
+		if err != nil {
+			if e.err == nil {
+				e.err = fmt.Errorf("scalarui: export %s: %w", name, err)
+			}
+			return
+		}
+		if len(data) == 0 {
+			return
+		}
+		e.write(name, data)
+	}
+}
+
+// page renders config to name; basePath leads from the page to the site root
+func (e *exporter) page(name string, config *Config, basePath string, mounted bool) {
+	if e.err != nil {
+		return
+	}
+	out, err := renderTemplate(config, RenderOptions{basePath: basePath, mounted: mounted})
+	if err != nil {
+		e.err = fmt.Errorf("scalarui: export %s: %w", name, err)
+		return
+	}
+	e.write(name, []byte(out))
+}
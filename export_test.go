@@ -0,0 +1,74 @@
+package scalarui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pets.yaml":  {Data: []byte("openapi: 3.0.3\ninfo:\n  title: Pets\n  version: '1'\npaths: {}\n")},
+		"users.json": {Data: []byte(`{"openapi": "3.1.0", "info": {"title": "Users", "version": "1"}, "paths": {}}`)},
+	}
+	config, err := NewPortalFromDir(fsys, "*")
+	if err != nil {
+		t.Fatalf("NewPortalFromDir: %v", err)
+	}
+	bundle := NewBundle("1.0.0", []byte("// scalar"))
+	config.WithBundle(bundle)
+	config.HotReloadURL = "/hot-reload"
+
+	dir := t.TempDir()
+	icon := filepath.Join(dir, "icon.png")
+	if err := os.WriteFile(icon, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	site := filepath.Join(dir, "site")
+	if err := New(config).Export(site, ExportOptions{Favicon: icon}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(site, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		return string(data)
+	}
+	for _, name := range []string{"scalar.js", "favicon.png", "pets/openapi.json", "users/openapi.yaml"} {
+		read(name)
+	}
+	if doc := read("users/openapi.yaml"); !strings.Contains(doc, "title: Users") {
+		t.Errorf("users/openapi.yaml was not converted:\n%s", doc)
+	}
+
+	index := read("index.html")
+	for _, want := range []string{`"./pets/openapi.yaml"`, `"./users/openapi.json"`, `src="./scalar.js?v=` + bundle.digest + `"`, `href="favicon.png"`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html does not contain %s", want)
+		}
+	}
+	if strings.Contains(index, "hot-reload") {
+		t.Errorf("index.html uses hot reload")
+	}
+
+	page := read("pets/index.html")
+	for _, want := range []string{`"url": "./openapi.yaml"`, `src="../scalar.js?v=` + bundle.digest + `"`, `href="../favicon.png"`, "<title>Pets</title>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("pets/index.html does not contain %s", want)
+		}
+	}
+	if strings.Contains(page, "users") {
+		t.Errorf("pets/index.html lists other sources")
+	}
+}
+
+func TestExportRejectsLocalProxy(t *testing.T) {
+	config := NewConfig().WithLocalProxy("/docs/proxy", "api.example.com")
+	if err := New(config).Export(t.TempDir(), ExportOptions{}); err == nil {
+		t.Errorf("Export with a local proxy succeeded")
+	}
+}
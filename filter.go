@@ -0,0 +1,490 @@
+package scalarui
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SpecFilter selects the part of a document published to one audience. An
+// operation is kept when it passes every set option.
+type SpecFilter struct {
+	IncludeTags  []string // Keep only operations with one of these tags
+	ExcludeTags  []string // Drop operations with any of these tags
+	IncludePaths []string // Keep only paths below one of these prefixes, matched by segment
+	ExcludePaths []string // Drop paths below any of these prefixes, matched by segment
+
+	// Drop operations, path items, schemas and schema properties marked
+	// x-internal: true. Operations that still use a dropped schema are
+	// dropped as well.
+	ExcludeInternal bool
+}
+
+// Filter returns a copy of the document with only the operations f keeps.
+// Components, security schemes and tags nothing refers to afterwards are
+// pruned. What had to be dropped beyond f's rules is listed in Warnings.
+func (s *Spec) Filter(f SpecFilter) (*Spec, error) {
+	doc := deepCopy(reflect.ValueOf(s.Doc)).Interface().(map[string]interface{})
+
+	sf := &specFilter{filter: f, doc: doc, version: s.Version}
+	sf.apply()
+
+	spec, err := s.derive(doc)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: filter spec: %w", err)
+	}
+	spec.Warnings = append(spec.Warnings, sf.warnings...)
+	if n := len(spec.filters); n == 0 || !reflect.DeepEqual(spec.filters[n-1], f) {
+		// Filtering twice with the same filter changes nothing
+		spec.filters = append(spec.filters, f)
+	}
+	return spec, nil
+}
+
+// WithFilter filters Content and every source without its own filter
+func (c *Config) WithFilter(f SpecFilter) *Config {
+	c.Filter = &f
+	return c
+}
+
+// filterContent returns content filtered by f, or nil when there is no
+// filter or no document to filter
+func filterContent(content interface{}, f *SpecFilter) (*Spec, error) {
+	if f == nil {
+		return nil, nil
+	}
+	spec, err := specFromContent(content)
+	if err != nil || spec == nil {
+		return nil, err
+	}
+	return spec.Filter(*f)
+}
+
+type specFilter struct {
+	filter   SpecFilter
+	doc      map[string]interface{}
+	version  SpecVersion
+	warnings []string
+}
+
+func (sf *specFilter) warnf(ptr, format string, args ...interface{}) {
+	sf.warnings = append(sf.warnings, ptr+": "+fmt.Sprintf(format, args...))
+}
+
+func (sf *specFilter) apply() {
+	sf.filterOperations()
+
+	if sf.filter.ExcludeInternal {
+		removed := map[string]bool{}
+		for _, schema := range sf.internalSchemas() {
+			removed[schema] = true
+		}
+		stripInternalProperties(sf.doc, removed)
+		sf.dropUsersOf(removed)
+		for unit := range removed {
+			sf.deleteUnit(unit)
+		}
+	}
+
+	sf.pruneComponents()
+	sf.pruneSecuritySchemes()
+	sf.pruneTags()
+}
+
+/* ------------------------------------------------------------- */
+/* Operations */
+/* ------------------------------------------------------------- */
+
+// filterOperations drops the operations the filter rules out, and path
+// items left without operations
+func (sf *specFilter) filterOperations() {
+	for _, section := range []string{"paths", "webhooks"} {
+		items, _ := sf.doc[section].(map[string]interface{})
+		for _, name := range sortedKeys(items) {
+			item, _ := items[name].(map[string]interface{})
+			if item == nil || isExtension(name) {
+				continue
+			}
+			if section == "paths" && !sf.keepPath(name) || sf.filter.ExcludeInternal && item["x-internal"] == true {
+				delete(items, name)
+				continue
+			}
+			for _, method := range httpMethods {
+				if op, ok := item[method].(map[string]interface{}); ok && !sf.keepOperation(op) {
+					delete(item, method)
+				}
+			}
+			if !hasOperations(item) {
+				delete(items, name)
+			}
+		}
+	}
+}
+
+func (sf *specFilter) keepPath(p string) bool {
+	for _, prefix := range sf.filter.ExcludePaths {
+		if pathBelow(p, prefix) {
+			return false
+		}
+	}
+	if len(sf.filter.IncludePaths) == 0 {
+		return true
+	}
+	for _, prefix := range sf.filter.IncludePaths {
+		if pathBelow(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathBelow reports whether p is prefix or lies below it. Whole segments are
+// matched, so "/admin" covers "/admin/keys" but not "/administrators".
+func pathBelow(p, prefix string) bool {
+	return p == prefix || strings.HasPrefix(p, strings.TrimRight(prefix, "/")+"/")
+}
+
+func (sf *specFilter) keepOperation(op map[string]interface{}) bool {
+	if sf.filter.ExcludeInternal && op["x-internal"] == true {
+		return false
+	}
+	tags := stringList(op["tags"])
+	for _, tag := range tags {
+		if contains(sf.filter.ExcludeTags, tag) {
+			return false
+		}
+	}
+	if len(sf.filter.IncludeTags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if contains(sf.filter.IncludeTags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOperations reports whether a path item (or a $ref to one) is left
+func hasOperations(item map[string]interface{}) bool {
+	if _, ok := item["$ref"]; ok {
+		return true
+	}
+	for _, method := range httpMethods {
+		if _, ok := item[method]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+/* ------------------------------------------------------------- */
+/* Internal Schemas */
+/* ------------------------------------------------------------- */
+
+// internalSchemas returns the units of schema components marked internal
+func (sf *specFilter) internalSchemas() []string {
+	var out []string
+	for _, unit := range sf.units() {
+		if !strings.HasPrefix(unit, "/components/schemas/") && !strings.HasPrefix(unit, "/definitions/") {
+			continue
+		}
+		if schema, ok := resolvePointer(sf.doc, "#"+unit).(map[string]interface{}); ok && schema["x-internal"] == true {
+			out = append(out, unit)
+		}
+	}
+	return out
+}
+
+// stripInternalProperties deletes schema properties marked internal or
+// referring to a removed schema, along with their required entries
+func stripInternalProperties(v interface{}, removed map[string]bool) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if props, ok := node["properties"].(map[string]interface{}); ok {
+			for name, prop := range props {
+				p, _ := prop.(map[string]interface{})
+				ref, _ := p["$ref"].(string)
+				if p["x-internal"] == true || removed[refUnit(ref)] {
+					delete(props, name)
+					if required, ok := node["required"]; ok {
+						node["required"] = without(required, name)
+					}
+				}
+			}
+			if required, ok := node["required"].([]interface{}); ok && len(required) == 0 {
+				delete(node, "required")
+			}
+		}
+		for _, child := range node {
+			stripInternalProperties(child, removed)
+		}
+	case []interface{}:
+		for _, item := range node {
+			stripInternalProperties(item, removed)
+		}
+	}
+}
+
+// dropUsersOf removes whatever still refers to a removed unit: components
+// are added to removed, operations and path items are deleted
+func (sf *specFilter) dropUsersOf(removed map[string]bool) {
+	for changed := true; changed; {
+		changed = false
+		for _, unit := range sf.units() {
+			if removed[unit] {
+				continue
+			}
+			if used := firstRef(resolvePointer(sf.doc, "#"+unit), removed); used != "" {
+				removed[unit] = true
+				changed = true
+				sf.warnf(unit, "uses internal %s; dropped", used)
+			}
+		}
+	}
+
+	for _, section := range []string{"paths", "webhooks"} {
+		items, _ := sf.doc[section].(map[string]interface{})
+		for _, name := range sortedKeys(items) {
+			item, _ := items[name].(map[string]interface{})
+			ptr := pointer("/"+section, name)
+			if used := firstRef(item["parameters"], removed); used != "" {
+				sf.warnf(ptr, "uses internal %s; dropped", used)
+				delete(items, name)
+				continue
+			}
+			for _, method := range httpMethods {
+				if used := firstRef(item[method], removed); used != "" {
+					sf.warnf(pointer(ptr, method), "uses internal %s; dropped", used)
+					delete(item, method)
+				}
+			}
+			if item != nil && !hasOperations(item) {
+				delete(items, name)
+			}
+		}
+	}
+}
+
+// firstRef returns the first unit in removed that v refers to, if any
+func firstRef(v interface{}, removed map[string]bool) string {
+	var refs []string
+	collectRefs(v, &refs)
+	sort.Strings(refs)
+	for _, ref := range refs {
+		if removed[refUnit(ref)] {
+			return refUnit(ref)
+		}
+	}
+	return ""
+}
+
+// without returns list minus value
+func without(list interface{}, value string) interface{} {
+	items, ok := list.([]interface{})
+	if !ok {
+		return list
+	}
+	out := items[:0]
+	for _, item := range items {
+		if item != value {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+/* ------------------------------------------------------------- */
+/* Pruning */
+/* ------------------------------------------------------------- */
+
+// units lists the pointers of every reusable object: components entries,
+// or Swagger 2.0 definitions, parameters and responses
+func (sf *specFilter) units() []string {
+	var out []string
+	add := func(base string, container interface{}) {
+		m, _ := container.(map[string]interface{})
+		for _, name := range sortedKeys(m) {
+			if !isExtension(name) {
+				out = append(out, pointer(base, name))
+			}
+		}
+	}
+
+	if sf.version == Swagger20 {
+		for _, section := range []string{"definitions", "parameters", "responses"} {
+			add("/"+section, sf.doc[section])
+		}
+		return out
+	}
+	components, _ := sf.doc["components"].(map[string]interface{})
+	for _, kind := range sortedKeys(components) {
+		if kind != "securitySchemes" && !isExtension(kind) {
+			add(pointer("/components", kind), components[kind])
+		}
+	}
+	return out
+}
+
+// refUnit returns the unit a local reference points into, e.g.
+// "/components/schemas/User" for "#/components/schemas/User/properties/id"
+func refUnit(ref string) string {
+	if !strings.HasPrefix(ref, "#/") {
+		return ""
+	}
+	tokens := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	n := 3
+	if tokens[0] != "components" {
+		n = 2
+	}
+	if len(tokens) < n {
+		return ""
+	}
+	return "/" + strings.Join(tokens[:n], "/")
+}
+
+// collectRefs appends every $ref in v
+func collectRefs(v interface{}, refs *[]string) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				*refs = append(*refs, ref)
+				continue
+			}
+			collectRefs(child, refs)
+		}
+	case []interface{}:
+		for _, item := range node {
+			collectRefs(item, refs)
+		}
+	}
+}
+
+// pruneComponents deletes reusable objects nothing outside them refers to,
+// directly or through other components
+func (sf *specFilter) pruneComponents() {
+	var refs []string
+	for key, value := range sf.doc {
+		if key != "components" && key != "definitions" && key != "parameters" && key != "responses" {
+			collectRefs(value, &refs)
+		}
+	}
+
+	reached := map[string]bool{}
+	for len(refs) > 0 {
+		unit := refUnit(refs[len(refs)-1])
+		refs = refs[:len(refs)-1]
+		if unit == "" || reached[unit] {
+			continue
+		}
+		reached[unit] = true
+		collectRefs(resolvePointer(sf.doc, "#"+unit), &refs)
+	}
+
+	for _, unit := range sf.units() {
+		if !reached[unit] {
+			sf.deleteUnit(unit)
+		}
+	}
+	if components, ok := sf.doc["components"].(map[string]interface{}); ok {
+		for kind, entries := range components {
+			if m, ok := entries.(map[string]interface{}); ok && len(m) == 0 {
+				delete(components, kind)
+			}
+		}
+		if len(components) == 0 {
+			delete(sf.doc, "components")
+		}
+	}
+}
+
+// pruneSecuritySchemes deletes schemes no security requirement names
+func (sf *specFilter) pruneSecuritySchemes() {
+	used := map[string]bool{}
+	addRequirements := func(v interface{}) {
+		list, _ := v.([]interface{})
+		for _, req := range list {
+			m, _ := req.(map[string]interface{})
+			for name := range m {
+				used[name] = true
+			}
+		}
+	}
+	addRequirements(sf.doc["security"])
+	sf.eachOperation(func(op map[string]interface{}) { addRequirements(op["security"]) })
+
+	for name := range securitySchemes(sf.doc) {
+		if !used[name] {
+			delete(securitySchemes(sf.doc), name)
+		}
+	}
+}
+
+// pruneTags deletes tag definitions, and x-tagGroups entries, that no
+// remaining operation uses
+func (sf *specFilter) pruneTags() {
+	used := map[string]bool{}
+	sf.eachOperation(func(op map[string]interface{}) {
+		for _, tag := range stringList(op["tags"]) {
+			used[tag] = true
+		}
+	})
+
+	if tags, ok := sf.doc["tags"].([]interface{}); ok {
+		kept := []interface{}{}
+		for _, tag := range tags {
+			m, _ := tag.(map[string]interface{})
+			if name, _ := m["name"].(string); used[name] {
+				kept = append(kept, tag)
+			}
+		}
+		sf.doc["tags"] = kept
+	}
+
+	if groups, ok := sf.doc["x-tagGroups"].([]interface{}); ok {
+		kept := []interface{}{}
+		for _, group := range groups {
+			m, _ := group.(map[string]interface{})
+			if m == nil {
+				continue
+			}
+			var names []interface{}
+			for _, name := range stringList(m["tags"]) {
+				if used[name] {
+					names = append(names, name)
+				}
+			}
+			if len(names) > 0 {
+				m["tags"] = names
+				kept = append(kept, m)
+			}
+		}
+		sf.doc["x-tagGroups"] = kept
+	}
+}
+
+// eachOperation calls fn for every operation in paths and webhooks
+func (sf *specFilter) eachOperation(fn func(op map[string]interface{})) {
+	for _, section := range []string{"paths", "webhooks"} {
+		items, _ := sf.doc[section].(map[string]interface{})
+		for _, item := range items {
+			m, _ := item.(map[string]interface{})
+			for _, method := range httpMethods {
+				if op, ok := m[method].(map[string]interface{}); ok {
+					fn(op)
+				}
+			}
+		}
+	}
+}
+
+// deleteUnit deletes a reusable object by pointer
+func (sf *specFilter) deleteUnit(unit string) {
+	i := strings.LastIndex(unit, "/")
+	if container, ok := resolvePointer(sf.doc, "#"+unit[:i]).(map[string]interface{}); ok {
+		name := strings.NewReplacer("~1", "/", "~0", "~").Replace(unit[i+1:])
+		delete(container, name)
+	}
+}
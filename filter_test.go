@@ -0,0 +1,173 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpecFilter(t *testing.T) {
+	spec, err := LoadSpecFile("testdata/specs/filter.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   SpecFilter
+		want     map[string]interface{} // pointer -> value, nil for absent
+		warnings []string
+	}{
+		{
+			name:   "exclude internal",
+			filter: SpecFilter{ExcludeInternal: true},
+			want: map[string]interface{}{
+				"/paths/~1users/get/operationId":                   "listUsers",
+				"/paths/~1users/post":                              nil,
+				"/paths/~1users~1{id}~1audit":                      nil,
+				"/paths/~1admin~1flags":                            nil,
+				"/paths/~1invoices/get/operationId":                "listInvoices",
+				"/components/schemas/User/properties/id/type":      "string",
+				"/components/schemas/User/properties/passwordHash": nil,
+				"/components/schemas/User/required":                []interface{}{"id"},
+				"/components/schemas/Address/type":                 "object",
+				"/components/schemas/AuditLog":                     nil,
+				"/components/schemas/AuditEntry":                   nil,
+				"/components/schemas/Flag":                         nil,
+				"/components/securitySchemes/apiKey/type":          "apiKey",
+				"/components/securitySchemes/basic":                nil,
+				"/tags":                                            []interface{}{map[string]interface{}{"name": "users"}, map[string]interface{}{"name": "billing"}},
+				"/x-tagGroups/0/name":                              "Public",
+				"/x-tagGroups/1":                                   nil,
+			},
+			warnings: []string{
+				"/components/schemas/AuditLog: uses internal /components/schemas/AuditEntry; dropped",
+				"/paths/~1users~1{id}~1audit/get: uses internal /components/schemas/AuditLog; dropped",
+			},
+		},
+		{
+			name:   "include tags",
+			filter: SpecFilter{IncludeTags: []string{"billing"}},
+			want: map[string]interface{}{
+				"/paths/~1users":                          nil,
+				"/paths/~1invoices/get/operationId":       "listInvoices",
+				"/components/schemas/Invoice/type":        "object",
+				"/components/schemas/User":                nil,
+				"/components/securitySchemes/bearer/type": "http",
+				"/components/securitySchemes/basic":       nil,
+				"/tags":                                   []interface{}{map[string]interface{}{"name": "billing"}},
+				"/x-tagGroups/0/tags":                     []interface{}{"billing"},
+			},
+		},
+		{
+			name:   "paths",
+			filter: SpecFilter{IncludePaths: []string{"/users", "/admin"}, ExcludePaths: []string{"/users/"}, ExcludeTags: []string{"admin"}},
+			want: map[string]interface{}{
+				"/paths/~1users/post/operationId":    "importUsers",
+				"/paths/~1users~1{id}~1audit":        nil,
+				"/paths/~1admin~1flags":              nil,
+				"/paths/~1invoices":                  nil,
+				"/components/schemas/AuditLog":       nil,
+				"/components/schemas/Address/type":   "object",
+				"/components/securitySchemes/apiKey": nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := spec.Filter(tt.filter)
+			if err != nil {
+				t.Fatalf("Filter: %v", err)
+			}
+			if err := filtered.Validate(); err != nil {
+				t.Errorf("filtered document is invalid:\n%v", err)
+			}
+			if filtered.Format != FormatYAML {
+				t.Errorf("Format = %s, want yaml", filtered.Format)
+			}
+			for ptr, value := range tt.want {
+				if got := resolvePointer(filtered.Doc, "#"+ptr); !reflect.DeepEqual(got, value) {
+					t.Errorf("%s = %v, want %v", ptr, got, value)
+				}
+			}
+			if !reflect.DeepEqual(filtered.Warnings, tt.warnings) {
+				t.Errorf("Warnings:\n got %q\nwant %q", filtered.Warnings, tt.warnings)
+			}
+		})
+	}
+
+	if _, ok := spec.Doc["paths"].(map[string]interface{})["/admin/flags"]; !ok {
+		t.Errorf("Filter modified the original document")
+	}
+}
+
+func TestSpecFilterMatchesPathSegments(t *testing.T) {
+	spec, err := ParseSpec([]byte(`{"openapi": "3.0.3", "info": {"title": "API", "version": "1"}, "paths": {
+		"/public": {"get": {"responses": {"200": {"description": "ok"}}}},
+		"/public/docs": {"get": {"responses": {"200": {"description": "ok"}}}},
+		"/public-admin/keys": {"get": {"responses": {"200": {"description": "ok"}}}},
+		"/admin": {"get": {"responses": {"200": {"description": "ok"}}}},
+		"/admin/keys": {"get": {"responses": {"200": {"description": "ok"}}}},
+		"/administrators": {"get": {"responses": {"200": {"description": "ok"}}}}
+	}}`))
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+
+	tests := []struct {
+		filter SpecFilter
+		want   []string
+	}{
+		{SpecFilter{IncludePaths: []string{"/public"}}, []string{"/public", "/public/docs"}},
+		{SpecFilter{IncludePaths: []string{"/public/"}}, []string{"/public/docs"}},
+		{SpecFilter{ExcludePaths: []string{"/admin"}}, []string{"/administrators", "/public", "/public-admin/keys", "/public/docs"}},
+		{SpecFilter{IncludePaths: []string{"/"}, ExcludePaths: []string{"/public"}}, []string{"/admin", "/admin/keys", "/administrators", "/public-admin/keys"}},
+	}
+	for _, tt := range tests {
+		filtered, err := spec.Filter(tt.filter)
+		if err != nil {
+			t.Fatalf("Filter: %v", err)
+		}
+		got := sortedKeys(filtered.Doc["paths"].(map[string]interface{}))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v kept %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestScalarUIFiltersSources(t *testing.T) {
+	spec, err := LoadSpecFile("testdata/specs/filter.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	config := NewConfig().
+		WithFilter(SpecFilter{ExcludeInternal: true}).
+		WithSource(SourceConfig{Slug: "public", Content: spec}).
+		WithSource(SourceConfig{Slug: "billing", Content: spec, Filter: &SpecFilter{IncludeTags: []string{"billing"}}})
+	ui := New(config)
+
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		ui.Mount("/docs").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+	public, billing := get("/docs/public/openapi.yaml"), get("/docs/billing/openapi.yaml")
+	if strings.Contains(public, "passwordHash") || !strings.Contains(public, "listUsers") {
+		t.Errorf("public document was not filtered:\n%s", public)
+	}
+	if strings.Contains(billing, "listUsers") || !strings.Contains(billing, "listInvoices") {
+		t.Errorf("billing document was not filtered:\n%s", billing)
+	}
+	if w := ui.Warnings(); len(w) != 2 || !strings.HasPrefix(w[0], "sources[0]: /components/schemas/AuditLog") {
+		t.Errorf("Warnings = %q", w)
+	}
+
+	// The config keeps the unfiltered documents, so filters can be lifted
+	ui.Update(func(c *Config) { c.Filter = nil })
+	if public := get("/docs/public/openapi.yaml"); !strings.Contains(public, "listFlags") {
+		t.Errorf("document is still filtered after removing the filter:\n%s", public)
+	}
+}
@@ -0,0 +1,234 @@
+package scalarui
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Paths served below the Mount prefix
+const (
+	SpecJSONPath  = "/openapi.json"
+	SpecYAMLPath  = "/openapi.yaml"
+	HotReloadPath = "/hot-reload"
+)
+
+// ServeHTTP renders the HTML page for every GET or HEAD request, except that
+// paths ending in BundlePath get the self-hosted bundle (Config.Bundle). The
+// page loads it from /scalar.js, so route that path here as well:
+//
+//	mux.Handle("/docs", ui)
+//	mux.Handle(scalarui.BundlePath, ui)
+func (s *ScalarUI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r) {
+		return
+	}
+	if s.config().Bundle != nil && strings.HasSuffix(r.URL.Path, BundlePath) {
+		s.serveBundle(w, r)
+		return
+	}
+	s.servePage(w, r, RenderOptions{})
+}
+
+// Mount returns a handler serving a complete docs subtree below prefix:
+//
+//	prefix                     -> HTML page
+//	prefix/openapi.json        -> Config.Content as JSON
+//	prefix/openapi.yaml        -> Config.Content as YAML
+//	prefix/hot-reload          -> hot-reload version (SSE or plain text)
+//	prefix/scalar.js           -> self-hosted Scalar bundle (Config.Bundle)
+//	prefix/<slug>/openapi.json -> document of the Sources entry with that slug
+//	prefix/<slug>/openapi.yaml -> same, as YAML
+//	Config.ProxyURL            -> same-origin Try-It proxy (Config.Proxy)
+//
+// The page connects to prefix/hot-reload unless Config.HotReloadURL points
+// elsewhere. Register it on a ServeMux with a trailing slash:
+//
+//	mux.Handle("/docs/", ui.Mount("/docs"))
+func (s *ScalarUI) Mount(prefix string) http.Handler {
+	prefix = strings.TrimRight(prefix, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Match whole segments, so /docsopenapi.json stays outside /docs
+		if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		// Try-It requests use every method
+		if config := s.config(); config.Proxy != nil && r.URL.Path == config.ProxyURL {
+			config.Proxy.ServeHTTP(w, r)
+			return
+		}
+		if !allowMethod(w, r) {
+			return
+		}
+
+		rel := strings.TrimPrefix(r.URL.Path, prefix)
+		switch rel {
+		case "", "/":
+			s.servePage(w, r, RenderOptions{basePath: prefix, mounted: true, hotReload: true})
+		case HotReloadPath:
+			s.serveHotReload(w, r)
+		case BundlePath:
+			s.serveBundle(w, r)
+		default:
+			if slug, format, ok := parseDocumentPath(rel); ok {
+				s.serveSpec(w, r, slug, format)
+				return
+			}
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// servePage writes the rendered HTML page, with a fresh nonce and matching
+// Content-Security-Policy header when Config.ContentSecurityPolicy is set
+func (s *ScalarUI) servePage(w http.ResponseWriter, r *http.Request, opts RenderOptions) {
+	snap := s.current.Load()
+	config := snap.served
+
+	if config.ContentSecurityPolicy && opts.Nonce == "" {
+		nonce, err := newNonce()
+		if err != nil {
+			http.Error(w, "Error rendering UI", http.StatusInternalServerError)
+			return
+		}
+		opts.Nonce = nonce
+	}
+
+	var (
+		html []byte
+		etag string
+		err  error
+	)
+	provider := s.auth.Load()
+	if provider != nil {
+		var auth *AuthConfig
+		if auth, err = (*provider)(r); err == nil && auth != nil {
+			// Rendered for this user only, bypassing the page cache
+			var prefilled *Config
+			if prefilled, err = prefilledConfig(config, auth); err == nil {
+				var out string
+				out, err = renderTemplate(prefilled, opts)
+				html = []byte(out)
+			}
+		} else if err == nil {
+			html, _, err = snap.page(opts)
+		}
+	} else {
+		html, etag, err = snap.page(opts)
+	}
+	if err != nil {
+		http.Error(w, "Error rendering UI", http.StatusInternalServerError)
+		return
+	}
+
+	cacheControl := "no-cache"
+	if config.ContentSecurityPolicy {
+		// Nonces differ per response, so the page is never revalidated
+		scriptURL, _ := scriptSource(config, opts.basePath)
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy(config, snap.specServers, opts.Nonce, scriptURL))
+		cacheControl = "no-store"
+	}
+	if provider != nil {
+		// Pages may carry a user's credentials
+		cacheControl = "no-store"
+	}
+	s.setCacheControl(w, cacheControl, provider != nil)
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+		if notModified(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(html)
+}
+
+// serveSpec writes the inline document at slug ("" for Config.Content) in
+// format, converting it if it is stored in the other one
+func (s *ScalarUI) serveSpec(w http.ResponseWriter, r *http.Request, slug string, format SpecFormat) {
+	data, err := s.current.Load().document(slug, format)
+	if err != nil {
+		http.Error(w, "Error encoding spec", http.StatusInternalServerError)
+		return
+	}
+	if len(data) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	s.setCacheControl(w, "no-cache", false)
+	w.Write(data)
+}
+
+// setCacheControl sets the Cache-Control header, keeping responses out of
+// shared caches when they differ per user or per audience
+func (s *ScalarUI) setCacheControl(w http.ResponseWriter, value string, private bool) {
+	if private || s.private {
+		value += ", private"
+	}
+	w.Header().Set("Cache-Control", value)
+}
+
+// serveBundle writes the self-hosted Scalar bundle, if configured
+func (s *ScalarUI) serveBundle(w http.ResponseWriter, r *http.Request) {
+	bundle := s.config().Bundle
+	if bundle == nil {
+		http.NotFound(w, r)
+		return
+	}
+	bundle.ServeHTTP(w, r)
+}
+
+// allowMethod rejects everything but GET and HEAD
+func allowMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+/* ------------------------------------------------------------- */
+/* Spec Content */
+/* ------------------------------------------------------------- */
+
+// rawContent returns the bytes of an inline spec and the format they are in.
+// Specs, strings and byte slices are served as-is, anything else is JSON
+// encoded.
+func rawContent(content interface{}) ([]byte, SpecFormat, error) {
+	var data []byte
+	switch v := content.(type) {
+	case nil:
+		return nil, "", nil
+	case *Spec:
+		return v.Raw, v.Format, nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		b, err := json.Marshal(v)
+		return b, FormatJSON, err
+	}
+	return data, detectFormat(data), nil
+}
+
+// encodeContent returns an inline document in format, converting it when it
+// is stored in the other one
+func encodeContent(content interface{}, format SpecFormat) ([]byte, error) {
+	if spec, ok := content.(*Spec); ok {
+		return spec.Encode(format)
+	}
+	data, actual, err := rawContent(content)
+	if err != nil || len(data) == 0 || actual == format {
+		return data, err
+	}
+	if format == FormatYAML {
+		return JSONToYAML(data)
+	}
+	return YAMLToJSON(data)
+}
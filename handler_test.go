@@ -0,0 +1,159 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPageETag(t *testing.T) {
+	ui := New(NewConfig().WithTitle("Before"))
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		ui.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET = %d, ETag %q", first.Code, etag)
+	}
+
+	for _, header := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		if rec := get(header); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s = %d with %d bytes, want empty 304", header, rec.Code, rec.Body.Len())
+		}
+	}
+	if rec := get(`"other"`); rec.Code != http.StatusOK {
+		t.Errorf("If-None-Match for another page = %d, want 200", rec.Code)
+	}
+
+	ui.Update(func(c *Config) { c.Title = "After" })
+	rec := get(etag)
+	if rec.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match after Update = %d, want 200", rec.Code)
+	}
+	if next := rec.Header().Get("ETag"); next == "" || next == etag {
+		t.Errorf("ETag after Update = %q, was %q", next, etag)
+	}
+}
+
+func TestMountRoutes(t *testing.T) {
+	const spec = `{"openapi": "3.0.3", "info": {"title": "API", "version": "1"}}`
+	ui := New(NewConfig().
+		WithContent(spec).
+		WithSource(SourceConfig{Slug: "admin", Content: "openapi: 3.1.0\n"}))
+	mux := http.NewServeMux()
+	mux.Handle("/docs/", ui.Mount("/docs/"))
+	mux.Handle("/docs", ui.Mount("/docs"))
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{"/docs", http.StatusOK, "text/html; charset=utf-8"},
+		{"/docs/", http.StatusOK, "text/html; charset=utf-8"},
+		{"/docs/openapi.json", http.StatusOK, FormatJSON.ContentType()},
+		{"/docs/openapi.yaml", http.StatusOK, FormatYAML.ContentType()},
+		{"/docs/admin/openapi.json", http.StatusOK, FormatJSON.ContentType()},
+		{"/docs/admin/openapi.yaml", http.StatusOK, FormatYAML.ContentType()},
+		{"/docs/hot-reload", http.StatusOK, "text/plain; charset=utf-8"},
+		{"/docs/missing/openapi.json", http.StatusNotFound, ""},
+		{"/docs/other", http.StatusNotFound, ""},
+		{"/docs/scalar.js", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.status)
+			continue
+		}
+		if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("GET %s Content-Type = %q, want %q", tt.path, rec.Header().Get("Content-Type"), tt.contentType)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	if rec.Body.String() != spec {
+		t.Errorf("openapi.json = %q, want the content as set", rec.Body.String())
+	}
+}
+
+func TestMountMatchesWholeSegments(t *testing.T) {
+	handler := New(NewConfig().WithContent(`{"openapi": "3.0.3"}`)).Mount("/docs")
+	for _, path := range []string{"/docsopenapi.json", "/docs-old", "/doc", "/"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, rec.Code)
+		}
+	}
+
+	root := New(NewConfig().WithContent(`{"openapi": "3.0.3"}`)).Mount("/")
+	for _, path := range []string{"/", "/openapi.json"} {
+		rec := httptest.NewRecorder()
+		root.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Mount(\"/\") GET %s = %d, want 200", path, rec.Code)
+		}
+	}
+}
+
+func TestMountRejectsOtherMethods(t *testing.T) {
+	handler := New(NewConfig().WithContent(`{"openapi": "3.0.3"}`)).Mount("/docs")
+	for _, path := range []string{"/docs", "/docs/openapi.json", "/docs/hot-reload"} {
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+			if rec.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s = %d, want 405", method, path, rec.Code)
+			}
+			if allow := rec.Header().Get("Allow"); allow != "GET, HEAD" {
+				t.Errorf("%s %s Allow = %q, want \"GET, HEAD\"", method, path, allow)
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/docs/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("HEAD /docs/openapi.json = %d, want 200", rec.Code)
+	}
+}
+
+func TestMountedPageConnectsToHotReload(t *testing.T) {
+	page := func(handler http.Handler, path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+	hotReload := func(url string) string {
+		return `enableHotReload("` + strings.ReplaceAll(url, "/", `\/`) + `")`
+	}
+
+	ui := New(NewConfig())
+	if body := page(ui.Mount("/docs"), "/docs"); !strings.Contains(body, hotReload("/docs/hot-reload")) {
+		t.Errorf("mounted page does not connect to /docs/hot-reload:\n%s", body)
+	}
+	if body := page(ui.Mount("/"), "/"); !strings.Contains(body, hotReload("/hot-reload")) {
+		t.Errorf("page mounted at / does not connect to /hot-reload")
+	}
+	if body := page(ui, "/docs"); !strings.Contains(body, hotReload("")) {
+		t.Errorf("ServeHTTP page connects to a hot-reload endpoint it does not serve")
+	}
+
+	configured := New(NewConfig())
+	configured.Update(func(c *Config) { c.HotReloadURL = "/events" })
+	if body := page(configured.Mount("/docs"), "/docs"); !strings.Contains(body, hotReload("/events")) {
+		t.Errorf("mounted page ignores HotReloadURL")
+	}
+}
@@ -0,0 +1,90 @@
+package scalarui
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment line
+const sseKeepAlive = 25 * time.Second
+
+// reloadHub fans hot-reload versions out to connected event streams
+type reloadHub struct {
+	mu   sync.Mutex
+	subs map[chan int64]struct{}
+}
+
+// subscribe registers a stream; the channel only ever holds the latest version
+func (h *reloadHub) subscribe() chan int64 {
+	ch := make(chan int64, 1)
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan int64]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *reloadHub) unsubscribe(ch chan int64) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+}
+
+// publish delivers version to every stream without blocking on slow readers
+func (h *reloadHub) publish(version int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- version
+	}
+}
+
+// serveHotReload streams versions as Server-Sent Events to EventSource
+// clients and answers everything else with the version as plain text, which
+// the page polls when a proxy buffers the stream
+func (s *ScalarUI) serveHotReload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "%d", s.Version())
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	ch := s.reload.subscribe()
+	defer s.reload.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: 2000\n\nevent: version\ndata: %d\n\n", s.Version())
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case version := <-ch:
+			fmt.Fprintf(w, "event: version\ndata: %d\n\n", version)
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package scalarui
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHotReloadEventStream(t *testing.T) {
+	ui := New(NewConfig())
+	server := httptest.NewServer(ui.Mount("/docs"))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/docs"+HotReloadPath, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET hot-reload: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	// nextVersion returns the data of the next version event
+	nextVersion := func() string {
+		t.Helper()
+		event := false
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("stream closed")
+				}
+				if line == "event: version" {
+					event = true
+				} else if event && strings.HasPrefix(line, "data: ") {
+					return strings.TrimPrefix(line, "data: ")
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no version event")
+			}
+		}
+	}
+
+	if got, want := nextVersion(), strconv.FormatInt(ui.Version(), 10); got != want {
+		t.Errorf("initial version = %s, want %s", got, want)
+	}
+	ui.Reload()
+	if got, want := nextVersion(), strconv.FormatInt(ui.Version(), 10); got != want {
+		t.Errorf("version after Reload = %s, want %s", got, want)
+	}
+}
+
+func TestHotReloadPlainText(t *testing.T) {
+	ui := New(NewConfig())
+	ui.Reload()
+
+	rec := httptest.NewRecorder()
+	ui.Mount("/docs").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs"+HotReloadPath, nil))
+	body, _ := io.ReadAll(rec.Body)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if want := strconv.FormatInt(ui.Version(), 10); string(body) != want {
+		t.Errorf("body = %q, want %s", body, want)
+	}
+}
@@ -0,0 +1,61 @@
+package scalarui
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// JSFunc is raw JavaScript, such as a function expression, that is emitted
+// verbatim into the createApiReference config instead of as a JSON string.
+// Use it for callbacks, sorters and slug generators:
+//
+//	config.WithOnLoaded(scalarui.JSFunc(`() => console.log("loaded")`))
+type JSFunc string
+
+var jsFuncType = reflect.TypeOf(JSFunc(""))
+
+// MarshalJSON encodes the source as a plain string. Only renderTemplate
+// emits it as JavaScript, after taking it out of the config.
+func (f JSFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(f))
+}
+
+// jsFuncs holds the JSFunc sources taken out of a config before marshaling.
+// Each is replaced by marker plus its index; the marker is random per
+// render, so no string in the config can pose as a function.
+type jsFuncs struct {
+	marker  string
+	sources []JSFunc
+}
+
+// extractJSFuncs returns a copy of config with every JSFunc replaced by a
+// placeholder string
+func extractJSFuncs(config *Config) (*Config, *jsFuncs) {
+	funcs := &jsFuncs{marker: "__scalarui_jsfunc_" + rand.Text() + "_"}
+	copied := deepCopyFunc(reflect.ValueOf(config), func(v reflect.Value) (reflect.Value, bool) {
+		if v.Type() != jsFuncType {
+			return v, false
+		}
+		placeholder := JSFunc(funcs.marker + strconv.Itoa(len(funcs.sources)))
+		funcs.sources = append(funcs.sources, v.Interface().(JSFunc))
+		return reflect.ValueOf(placeholder), true
+	})
+	return copied.Interface().(*Config), funcs
+}
+
+// inline replaces the placeholders in marshaled JSON with their source.
+// "</" is escaped so a function cannot close the <script> block.
+func (f *jsFuncs) inline(data []byte) []byte {
+	if len(f.sources) == 0 {
+		return data
+	}
+	pattern := regexp.MustCompile(`"` + regexp.QuoteMeta(f.marker) + `(\d+)"`)
+	return pattern.ReplaceAllFunc(data, func(m []byte) []byte {
+		i, _ := strconv.Atoi(string(pattern.FindSubmatch(m)[1]))
+		return bytes.ReplaceAll([]byte(f.sources[i]), []byte("</"), []byte(`<\/`))
+	})
+}
@@ -0,0 +1,159 @@
+package scalarui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts every environment variable read by ApplyEnv
+const EnvPrefix = "SCALARUI_"
+
+// LoadConfig builds a config from NewConfig defaults, the YAML or JSON file
+// at path (skipped when path is empty) and SCALARUI_* environment variables,
+// in that order. The file uses the same keys as the Config JSON tags;
+// unknown keys and variables are errors.
+func LoadConfig(path string) (*Config, error) {
+	config := NewConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("scalarui: load config: %w", err)
+		}
+		if err := config.decode(data); err != nil {
+			return nil, fmt.Errorf("scalarui: %s: %w", path, err)
+		}
+	}
+
+	if err := config.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// decode applies a JSON or YAML document on top of c
+func (c *Config) decode(data []byte) error {
+	if detectFormat(data) == FormatYAML {
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return err
+		}
+		converted, err := json.Marshal(yamlValue(&node))
+		if err != nil {
+			return err
+		}
+		data = converted
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(c)
+}
+
+// ApplyEnv overrides fields from environment variables named after their
+// JSON keys, e.g. SCALARUI_THEME=moon or SCALARUI_HIDE_SEARCH=true. Strings
+// are taken as-is, booleans accept strconv.ParseBool values and other
+// fields take JSON, e.g. SCALARUI_SERVERS='[{"url":"https://api.example.com"}]'.
+func (c *Config) ApplyEnv() error {
+	fields := envFields()
+
+	var names []string
+	for _, kv := range os.Environ() {
+		if name, _, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(name, EnvPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	v := reflect.ValueOf(c).Elem()
+	for _, name := range names {
+		index, ok := fields[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown variable %s", name))
+			continue
+		}
+		if err := setFromEnv(v.Field(index), os.Getenv(name)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("scalarui: environment: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// envFields maps variable names to Config field indexes
+func envFields() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		fields[EnvPrefix+upperSnake(key)] = i
+	}
+	return fields
+}
+
+// setFromEnv parses value into field according to its kind
+func setFromEnv(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+		return nil
+	case reflect.Pointer:
+		if field.Type().Elem().Kind() == reflect.Bool {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(Bool(b)))
+			return nil
+		}
+	}
+
+	ptr := reflect.New(field.Type())
+	if err := json.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+		return err
+	}
+	field.Set(ptr.Elem())
+	return nil
+}
+
+// upperSnake converts a camelCase key to UPPER_SNAKE_CASE:
+// hideSearch -> HIDE_SEARCH, baseServerURL -> BASE_SERVER_URL
+func upperSnake(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package scalarui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"docs.yaml", "title: Pet Store\ntheme: moon\nhideSearch: true\nservers:\n  - url: https://api.example.com\n"},
+		{"docs.json", `{"title": "Pet Store", "theme": "moon", "hideSearch": true, "servers": [{"url": "https://api.example.com"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(writeConfigFile(t, tt.name, tt.data))
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if config.Title != "Pet Store" || config.Theme != "moon" || config.HideSearch == nil || !*config.HideSearch {
+				t.Errorf("config = %+v", config)
+			}
+			if len(config.Servers) != 1 || config.Servers[0].URL != "https://api.example.com" {
+				t.Errorf("Servers = %+v", config.Servers)
+			}
+			// Defaults stay for keys the file leaves out
+			if config.ProxyURL != NewConfig().ProxyURL {
+				t.Errorf("ProxyURL = %q, want the default", config.ProxyURL)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	if _, err := LoadConfig(writeConfigFile(t, "docs.yaml", "title: X\nhideSerach: true\n")); err == nil || !strings.Contains(err.Error(), "hideSerach") {
+		t.Errorf("unknown file key: err = %v", err)
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("missing file: no error")
+	}
+
+	t.Setenv("SCALARUI_HIDE_SERACH", "true")
+	if _, err := LoadConfig(""); err == nil || !strings.Contains(err.Error(), "unknown variable SCALARUI_HIDE_SERACH") {
+		t.Errorf("unknown variable: err = %v", err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("SCALARUI_THEME", "moon")
+	t.Setenv("SCALARUI_HIDE_SEARCH", "false")
+	t.Setenv("SCALARUI_BASE_SERVER_URL", "https://api.example.com")
+	t.Setenv("SCALARUI_SERVERS", `[{"url": "https://staging.example.com"}]`)
+
+	config, err := LoadConfig(writeConfigFile(t, "docs.yaml", "theme: purple\nhideSearch: true\n"))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	// Variables override the file
+	if config.Theme != "moon" || config.HideSearch == nil || *config.HideSearch {
+		t.Errorf("Theme = %q, HideSearch = %v", config.Theme, config.HideSearch)
+	}
+	if config.BaseServerURL != "https://api.example.com" {
+		t.Errorf("BaseServerURL = %q", config.BaseServerURL)
+	}
+	if len(config.Servers) != 1 || config.Servers[0].URL != "https://staging.example.com" {
+		t.Errorf("Servers = %+v", config.Servers)
+	}
+
+	t.Setenv("SCALARUI_HIDE_SEARCH", "maybe")
+	t.Setenv("SCALARUI_SERVERS", "not json")
+	err = NewConfig().ApplyEnv()
+	for _, want := range []string{"SCALARUI_HIDE_SEARCH", "SCALARUI_SERVERS"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("invalid %s: err = %v", want, err)
+		}
+	}
+}
+
+func TestUpperSnake(t *testing.T) {
+	got := map[string]string{}
+	for _, key := range []string{"hideSearch", "baseServerURL", "proxyUrl", "withDefaultFonts", "theme", "URLPath", "h2Title"} {
+		got[key] = upperSnake(key)
+	}
+	want := map[string]string{
+		"hideSearch":       "HIDE_SEARCH",
+		"baseServerURL":    "BASE_SERVER_URL",
+		"proxyUrl":         "PROXY_URL",
+		"withDefaultFonts": "WITH_DEFAULT_FONTS",
+		"theme":            "THEME",
+		"URLPath":          "URL_PATH",
+		"h2Title":          "H2_TITLE",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("upperSnake:\n got %v\nwant %v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package scalarui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultSourceRule picks the index of the default document among sources
+type DefaultSourceRule func(sources []SourceConfig) int
+
+// NewPortalFromDir creates a config with one source per OpenAPI document in
+// fsys matching pattern (fs.Glob syntax, e.g. "specs/*.yaml"). Files that
+// are not OpenAPI documents are skipped.
+//
+// Titles come from info.title, falling back to the file name, and slugs are
+// derived from the titles, numbered when they collide. Under Mount every
+// document is served at prefix/<slug>/openapi.json or openapi.yaml.
+func NewPortalFromDir(fsys fs.FS, pattern string) (*Config, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: portal: %w", err)
+	}
+
+	config := NewConfig()
+	slugs := map[string]bool{}
+	for _, name := range matches {
+		spec, err := LoadSpecFS(fsys, name)
+		if errors.Is(err, ErrNotSpec) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scalarui: portal: %w", err)
+		}
+
+		base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+		title := spec.Title()
+		if title == "" {
+			title = base
+		}
+		slug := slugify(title)
+		if slug == "" {
+			slug = slugify(base)
+		}
+		if slugs[slug] {
+			n := 2
+			for slugs[slug+"-"+strconv.Itoa(n)] {
+				n++
+			}
+			slug += "-" + strconv.Itoa(n)
+		}
+		slugs[slug] = true
+
+		config.WithSource(SourceConfig{Title: title, Slug: slug, Content: spec})
+	}
+	if len(config.Sources) == 0 {
+		return nil, fmt.Errorf("scalarui: portal: no OpenAPI documents match %q", pattern)
+	}
+	return config, nil
+}
+
+// WithDefaultSource marks the source chosen by rule as the default
+func (c *Config) WithDefaultSource(rule DefaultSourceRule) *Config {
+	if len(c.Sources) == 0 {
+		return c
+	}
+	chosen := rule(c.Sources)
+	for i := range c.Sources {
+		c.Sources[i].Default = i == chosen
+	}
+	return c
+}
+
+// HighestVersion picks the document with the highest info.version, comparing
+// numeric components ("2.10.0" > "2.9.1"). Ties go to the first source.
+func HighestVersion(sources []SourceConfig) int {
+	best := 0
+	for i := range sources {
+		if compareVersions(sourceVersion(sources[i]), sourceVersion(sources[best])) > 0 {
+			best = i
+		}
+	}
+	return best
+}
+
+// sourceVersion returns info.version of an inline document
+func sourceVersion(src SourceConfig) string {
+	spec, err := specFromContent(src.Content)
+	if err != nil || spec == nil {
+		return ""
+	}
+	info, _ := spec.Doc["info"].(map[string]interface{})
+	if v, ok := info["version"]; ok {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// compareVersions compares the numeric components of two version strings
+func compareVersions(a, b string) int {
+	isSep := func(r rune) bool { return !unicode.IsDigit(r) }
+	pa, pb := strings.FieldsFunc(a, isSep), strings.FieldsFunc(b, isSep)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na > nb {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+// slugify lowercases s and joins its letters and digits with dashes
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+/* ------------------------------------------------------------- */
+/* Serving Documents */
+/* ------------------------------------------------------------- */
+
+// documentPath returns the URL Mount serves a document at; slug is empty
+// for Config.Content
+func documentPath(basePath, slug string, format SpecFormat) string {
+	if slug != "" {
+		basePath += "/" + slug
+	}
+	if format == FormatYAML {
+		return basePath + SpecYAMLPath
+	}
+	return basePath + SpecJSONPath
+}
+
+// linkDocuments replaces inline Content, and the content of slugged sources,
+// with the URLs Mount serves them at. The page stays small and Scalar's
+// download button fetches from this server. config is copied only if
+// something changes.
+func linkDocuments(config *Config, basePath string) *Config {
+	linked := config
+	link := func() {
+		if linked == config {
+			linked = config.Clone()
+		}
+	}
+
+	if format := contentFormat(config.Content); format != "" {
+		link()
+		linked.Content = nil
+		linked.URL = documentPath(basePath, "", format)
+	}
+	for i, src := range config.Sources {
+		format := contentFormat(src.Content)
+		if format == "" || src.Slug == "" {
+			continue
+		}
+		link()
+		linked.Sources[i].Content = nil
+		linked.Sources[i].URL = documentPath(basePath, src.Slug, format)
+	}
+	return linked
+}
+
+// contentFormat returns the format inline content is stored in, or "" when
+// there is none
+func contentFormat(content interface{}) SpecFormat {
+	data, format, err := rawContent(content)
+	if err != nil || len(data) == 0 {
+		return ""
+	}
+	return format
+}
+
+// parseDocumentPath splits a path below the Mount prefix, such as
+// "/petstore/openapi.json", into a slug and format. The slug is empty for
+// Config.Content.
+func parseDocumentPath(rel string) (string, SpecFormat, bool) {
+	dir, file := path.Split(rel)
+	slug := strings.Trim(dir, "/")
+	if strings.Contains(slug, "/") {
+		return "", "", false
+	}
+	switch "/" + file {
+	case SpecJSONPath:
+		return slug, FormatJSON, true
+	case SpecYAMLPath:
+		return slug, FormatYAML, true
+	}
+	return "", "", false
+}
+
+// documentContent returns the inline document at slug
+func documentContent(config *Config, slug string) interface{} {
+	if slug == "" {
+		return config.Content
+	}
+	for _, src := range config.Sources {
+		if src.Slug == slug {
+			return src.Content
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package scalarui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewPortalFromDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"specs/a-v1.yaml":    {Data: []byte("openapi: 3.0.3\ninfo:\n  title: Pet Store\n  version: 1.9.0\npaths: {}\n")},
+		"specs/b-v2.json":    {Data: []byte(`{"openapi": "3.1.0", "info": {"title": "Pet Store", "version": "1.10.0"}, "paths": {}}`)},
+		"specs/c-users.yaml": {Data: []byte("openapi: 3.1.0\ninfo:\n  version: '1'\npaths: {}\n")},
+		"specs/values.yaml":  {Data: []byte("replicas: 3\n")},
+	}
+
+	config, err := NewPortalFromDir(fsys, "specs/*")
+	if err != nil {
+		t.Fatalf("NewPortalFromDir: %v", err)
+	}
+	config.WithDefaultSource(HighestVersion)
+
+	var got []string
+	for _, src := range config.Sources {
+		got = append(got, src.Title+"|"+src.Slug)
+	}
+	if want := "Pet Store|pet-store Pet Store|pet-store-2 c-users|c-users"; strings.Join(got, " ") != want {
+		t.Errorf("sources = %q, want %q", got, want)
+	}
+	if !config.Sources[1].Default || config.Sources[0].Default {
+		t.Errorf("HighestVersion did not pick 1.10.0")
+	}
+
+	handler := New(config).Mount("/docs")
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	page := get("/docs").Body.String()
+	for _, url := range []string{`"/docs/pet-store/openapi.yaml"`, `"/docs/pet-store-2/openapi.json"`} {
+		if !strings.Contains(page, url) {
+			t.Errorf("page does not link %s", url)
+		}
+	}
+	if strings.Contains(page, "1.9.0") {
+		t.Errorf("page inlines a source document")
+	}
+
+	if rec := get("/docs/pet-store-2/openapi.json"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "1.10.0") {
+		t.Errorf("GET pet-store-2 = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get("/docs/missing/openapi.json"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing slug = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,192 @@
+package scalarui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Proxy limits used by NewProxy
+const (
+	DefaultProxyMaxBodySize = 10 << 20
+	DefaultProxyTimeout     = 30 * time.Second
+)
+
+// errHostNotAllowed is returned when a redirect leaves the allowlist
+var errHostNotAllowed = errors.New("upstream host is not allowed")
+
+// hopHeaders apply to a single connection and are never forwarded
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "TE", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// responseHeaders would let an upstream set state on, or loosen the policy
+// of, the docs origin. They are dropped along with every Access-Control-*
+// header, since CORS is settled between the page and the proxy.
+var responseHeaders = []string{
+	"Set-Cookie", "Set-Cookie2", "Clear-Site-Data",
+	"Content-Security-Policy", "Content-Security-Policy-Report-Only",
+}
+
+// Proxy is a same-origin replacement for proxy.scalar.com. Scalar sends
+// Try-It requests to ProxyURL?scalar_url=<target>; the proxy forwards the
+// method, headers and body to the target and streams the response back.
+//
+// Only hosts in AllowedHosts are reachable, including after redirects.
+// Cookies for the docs site are not forwarded, and upstream Set-Cookie
+// headers are dropped so they cannot land on the docs origin. Responses are
+// sent with "Content-Security-Policy: sandbox" and nosniff, so an upstream
+// that returns HTML cannot run script on the docs origin.
+type Proxy struct {
+	AllowedHosts []string          // "api.example.com", "api.example.com:8443" or "*.example.com"
+	MaxBodySize  int64             // Request body limit in bytes, 0 for none
+	Timeout      time.Duration     // Limit for the whole exchange, 0 for none
+	Transport    http.RoundTripper // nil uses http.DefaultTransport
+}
+
+// NewProxy creates a proxy for the given upstream hosts with the default limits
+func NewProxy(allowedHosts ...string) *Proxy {
+	return &Proxy{
+		AllowedHosts: allowedHosts,
+		MaxBodySize:  DefaultProxyMaxBodySize,
+		Timeout:      DefaultProxyTimeout,
+	}
+}
+
+// ServeHTTP forwards the request to the scalar_url query parameter
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("scalar_url")
+	if raw == "" {
+		http.Error(w, "Missing scalar_url", http.StatusBadRequest)
+		return
+	}
+	target, err := url.Parse(raw)
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		http.Error(w, "Invalid scalar_url", http.StatusBadRequest)
+		return
+	}
+	if !p.allowed(target) {
+		http.Error(w, "Upstream host is not allowed", http.StatusForbidden)
+		return
+	}
+	if p.MaxBodySize > 0 && r.ContentLength > p.MaxBodySize {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ctx := r.Context()
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	var body io.Reader
+	if r.Body != nil && r.Body != http.NoBody {
+		body = r.Body
+		if p.MaxBodySize > 0 {
+			body = http.MaxBytesReader(w, r.Body, p.MaxBodySize)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, target.String(), body)
+	if err != nil {
+		http.Error(w, "Invalid scalar_url", http.StatusBadRequest)
+		return
+	}
+	req.ContentLength = r.ContentLength
+	copyHeader(req.Header, r.Header)
+	req.Header.Del("Cookie")
+
+	client := &http.Client{
+		Transport: p.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !p.allowed(req.URL) {
+				return errHostNotAllowed
+			}
+			return nil
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, errHostNotAllowed):
+			http.Error(w, "Upstream host is not allowed", http.StatusForbidden)
+		case errors.Is(err, context.DeadlineExceeded):
+			http.Error(w, "Upstream timed out", http.StatusGatewayTimeout)
+		default:
+			http.Error(w, "Upstream request failed", http.StatusBadGateway)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	copyHeader(w.Header(), resp.Header)
+	for name := range w.Header() {
+		if strings.HasPrefix(name, "Access-Control-") {
+			w.Header().Del(name)
+		}
+	}
+	for _, name := range responseHeaders {
+		w.Header().Del(name)
+	}
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// allowed reports whether u's host matches an AllowedHosts entry. Entries
+// without a port match any port.
+func (p *Proxy) allowed(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	for _, pattern := range p.AllowedHosts {
+		pattern = strings.ToLower(pattern)
+		if h, pp, err := net.SplitHostPort(pattern); err == nil {
+			if pp != port {
+				continue
+			}
+			pattern = h
+		}
+		pattern = strings.Trim(pattern, "[]")
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// copyHeader copies end-to-end headers from src to dst
+func copyHeader(dst, src http.Header) {
+	for name, values := range src {
+		dst[name] = append([]string(nil), values...)
+	}
+	for _, name := range strings.Split(src.Get("Connection"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			dst.Del(name)
+		}
+	}
+	for _, name := range hopHeaders {
+		dst.Del(name)
+	}
+}
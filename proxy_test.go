@@ -0,0 +1,116 @@
+package scalarui
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProxyForwardsAllowedHosts(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		w.Header().Set("X-Cookie", r.Header.Get("Cookie"))
+		w.Header().Set("Set-Cookie", "upstream=1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+
+	ui := New(NewConfig().WithLocalProxy("/docs/proxy", target.Host))
+	handler := ui.Mount("/docs")
+
+	req := httptest.NewRequest(http.MethodPost, "/docs/proxy?scalar_url="+url.QueryEscape(upstream.URL+"/users"), strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Authorization", "Bearer t")
+	req.Header.Set("Cookie", "session=docs")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"name":"a"}` {
+		t.Fatalf("got %d %q", rec.Code, rec.Body.String())
+	}
+	h := rec.Header()
+	if h.Get("X-Method") != "POST" || h.Get("X-Auth") != "Bearer t" {
+		t.Errorf("method or auth header not forwarded: %v", h)
+	}
+	if h.Get("X-Cookie") != "" || h.Get("Set-Cookie") != "" {
+		t.Errorf("cookies crossed the proxy: %v", h)
+	}
+}
+
+func TestProxySandboxesResponses(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Content-Security-Policy", "script-src *")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Set-Cookie2", "upstream=1")
+		w.Write([]byte("<script>alert(document.cookie)</script>"))
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+
+	rec := httptest.NewRecorder()
+	NewProxy(target.Host).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/proxy?scalar_url="+url.QueryEscape(upstream.URL), nil))
+
+	h := rec.Header()
+	if rec.Code != http.StatusOK || h.Get("Content-Type") != "text/html" {
+		t.Fatalf("got %d %q", rec.Code, h.Get("Content-Type"))
+	}
+	if csp := h.Values("Content-Security-Policy"); len(csp) != 1 || csp[0] != "sandbox" {
+		t.Errorf("Content-Security-Policy = %q, want only sandbox", csp)
+	}
+	if h.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", h.Get("X-Content-Type-Options"))
+	}
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials", "Set-Cookie2"} {
+		if v := h.Get(name); v != "" {
+			t.Errorf("upstream %s crossed the proxy: %q", name, v)
+		}
+	}
+}
+
+func TestProxyRejects(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	slowURL, _ := url.Parse(slow.URL)
+
+	p := NewProxy(slowURL.Host, "*.example.com")
+	p.MaxBodySize = 4
+	p.Timeout = 50 * time.Millisecond
+
+	cases := []struct {
+		name   string
+		target string
+		body   string
+		want   int
+	}{
+		{"missing", "", "", http.StatusBadRequest},
+		{"scheme", "file:///etc/passwd", "", http.StatusBadRequest},
+		{"host", "https://evil.test/", "", http.StatusForbidden},
+		{"apex", "https://example.com/", "", http.StatusForbidden},
+		{"size", slow.URL, "too large", http.StatusRequestEntityTooLarge},
+		{"timeout", slow.URL, "", http.StatusGatewayTimeout},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := "/proxy"
+			if tc.target != "" {
+				target += "?scalar_url=" + url.QueryEscape(tc.target)
+			}
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, strings.NewReader(tc.body)))
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package scalarui
+
+import (
+	"encoding/base64"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+func TestRenderTemplateGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{"defaults", NewConfig()},
+		{"theme", NewConfig().WithTheme("purple").WithDarkMode(true)},
+		{"css", NewConfig().
+			WithCustomCSS(".scalar-app { --scalar-color-accent: #7c3aed }").
+			WithVariable("primary-color", "#7c3aed").
+			WithVariable("--font", "'Inter', sans-serif")},
+		{"favicon", func() *Config {
+			c := NewConfig().WithTitle("Pets")
+			c.Favicon = "/favicon.ico"
+			return c
+		}()},
+		{"description", NewConfig().WithDescription(`Quotes "and" <tags>`)},
+		{"hot_reload", func() *Config {
+			c := NewConfig().WithURL("/openapi.json")
+			c.HotReloadURL = "/docs/hot-reload"
+			return c
+		}()},
+		{"sidebar_off", NewConfig().WithSidebar(false).WithInteractive(false).WithDarkMode(false)},
+		{"js_func", NewConfig().
+			WithOnLoaded(JSFunc(`() => console.log("loaded </script>")`)).
+			WithTagsSorter(JSFunc("(a, b) => a.name.localeCompare(b.name)")).
+			WithOperationsSorter("method")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate(tt.config, RenderOptions{})
+			if err != nil {
+				t.Fatalf("renderTemplate: %v", err)
+			}
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderTemplateCSSCannotCloseStyle(t *testing.T) {
+	got, err := renderTemplate(NewConfig().WithCustomCSS("a{}</style><script>x()</script>"), RenderOptions{})
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	assertGolden(t, "css_escape", got)
+}
+
+func TestRenderTemplateNonce(t *testing.T) {
+	config := NewConfig().WithCustomCSS("a{}").WithVariable("accent", "red")
+	config.HotReloadURL = "/hot-reload"
+
+	got, err := renderTemplate(config, RenderOptions{Nonce: "bm9uY2U="})
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	assertGolden(t, "nonce", got)
+}
+
+func TestRenderTemplateStringsCannotPoseAsJSFunc(t *testing.T) {
+	// The placeholder format of earlier releases, and anything like it
+	marker := "__scalarui_jsfunc__" + base64.RawURLEncoding.EncodeToString([]byte("alert(document.cookie)"))
+	config := NewConfig().WithOnLoaded(JSFunc("() => {}")).WithTitle(marker)
+	config.Content = map[string]interface{}{"info": map[string]interface{}{"description": marker}}
+
+	got, err := renderTemplate(config, RenderOptions{})
+	if err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if strings.Contains(got, "alert(document.cookie)") {
+		t.Errorf("a string in the config was emitted as JavaScript:\n%s", got)
+	}
+	if !strings.Contains(got, `"description": "`+marker+`"`) {
+		t.Errorf("description is no longer a JSON string:\n%s", got)
+	}
+	if !strings.Contains(got, `"onLoaded": () => {}`) {
+		t.Errorf("JSFunc was not inlined:\n%s", got)
+	}
+}
+
+// assertGolden compares got with testdata/golden/<name>.html
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".html")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run go test -update): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match rendered output (run go test -update to accept):\n%s", path, got)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	_ "embed"
 	"encoding/json"
 	"html/template"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Embed the HTML template
@@ -12,30 +16,53 @@ import (
 //go:embed template.html
 var htmlTemplate string
 
+// pageTemplate is parsed once; html/template is safe for concurrent Execute
+var pageTemplate = template.Must(template.New("scalar").Parse(htmlTemplate))
+
 // TemplateData represents the data passed to the HTML template
 type TemplateData struct {
-	Title        string
-	Description  string
-	Favicon      string
-	CustomCSS    string
-	Variables    map[string]string
-	ConfigJSON   template.JS
-	HotReloadURL string
+	Title           string
+	Description     string
+	Favicon         string
+	CustomCSS       template.CSS
+	Variables       map[string]template.CSS
+	ConfigJSON      template.JS
+	HotReloadURL    string
+	ScriptURL       string
+	ScriptIntegrity string
+	Nonce           string
 }
 
-// ScalarUI represents a configured Scalar UI instance
+// RenderOptions holds per-request render settings
+type RenderOptions struct {
+	Nonce string // CSP nonce stamped on every inline <script> and <style>
+
+	basePath  string // Mount prefix, set by the handlers
+	mounted   bool   // Rendered by Mount, which serves source documents
+	hotReload bool   // Served by Mount, which serves basePath + HotReloadPath
+}
+
+// ScalarUI represents a configured Scalar UI instance. It is safe for
+// concurrent use: the config is held as an immutable snapshot that
+// SetConfig and Update replace atomically.
 type ScalarUI struct {
-	config *Config
+	current  atomic.Pointer[snapshot] // config and its rendered pages
+	updateMu sync.Mutex               // serializes SetConfig and Update
+	version  atomic.Int64             // hot-reload version
+	reload   reloadHub                // hot-reload event streams
+	auth     atomic.Pointer[AuthProvider]
+	private  bool // Serves one audience, so responses must not be shared
 }
 
-// New creates a new ScalarUI instance with the given configuration
+// New creates a new ScalarUI instance with a copy of the given configuration
 func New(config *Config) *ScalarUI {
 	if config == nil {
 		config = NewConfig()
 	}
-	return &ScalarUI{
-		config: config,
-	}
+	s := &ScalarUI{}
+	s.current.Store(newSnapshot(config.Clone()))
+	s.version.Store(time.Now().UnixNano())
+	return s
 }
 
 // NewWithDefaults creates a new ScalarUI instance with default configuration
@@ -43,50 +70,117 @@ func NewWithDefaults() *ScalarUI {
 	return New(NewConfig())
 }
 
-// SetConfig updates the configuration
+// SetConfig replaces the configuration with a copy of config
 func (s *ScalarUI) SetConfig(config *Config) {
-	s.config = config
+	if config == nil {
+		config = NewConfig()
+	}
+	s.updateMu.Lock()
+	s.current.Store(newSnapshot(config.Clone()))
+	s.updateMu.Unlock()
 }
 
-// GetConfig returns the current configuration
+// GetConfig returns a copy of the current configuration; changing it has no
+// effect until passed to SetConfig
 func (s *ScalarUI) GetConfig() *Config {
-	return s.config
+	return s.config().Clone()
+}
+
+// Update applies fn to a copy of the current configuration and swaps it in
+// as a whole. Concurrent updates are serialized, so none are lost.
+func (s *ScalarUI) Update(fn func(*Config)) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	config := s.config().Clone()
+	fn(config)
+	s.current.Store(newSnapshot(config))
+}
+
+// config returns the current snapshot's configuration, which must not be
+// modified
+func (s *ScalarUI) config() *Config {
+	return s.current.Load().config
+}
+
+// Version returns the current hot-reload version
+func (s *ScalarUI) Version() int64 {
+	return s.version.Load()
+}
+
+// Reload bumps the hot-reload version so open pages refresh
+func (s *ScalarUI) Reload() {
+	s.reload.publish(s.version.Add(1))
 }
 
 // Render generates the HTML string with the configured options
 func (s *ScalarUI) Render() (string, error) {
-	return renderTemplate(s.config)
+	return s.RenderWithOptions(RenderOptions{})
+}
+
+// RenderWithOptions generates the HTML string using per-request options
+func (s *ScalarUI) RenderWithOptions(opts RenderOptions) (string, error) {
+	out, _, err := s.current.Load().page(opts)
+	return string(out), err
 }
 
 // renderTemplate renders the HTML template with the given configuration
-func renderTemplate(config *Config) (string, error) {
+func renderTemplate(config *Config, opts RenderOptions) (string, error) {
 	// Convert config to JSON for JavaScript
-	configBytes, err := json.MarshalIndent(config, "", "    ")
+	if opts.mounted {
+		config = linkDocuments(config, opts.basePath)
+	}
+	withoutFuncs, funcs := extractJSFuncs(config)
+	configBytes, err := json.MarshalIndent(withoutFuncs, "", "    ")
 	if err != nil {
 		return "", err
 	}
 
-	// Prepare template data
-	data := TemplateData{
-		Title:        config.Title,
-		Description:  config.Description,
-		Favicon:      config.Favicon,
-		CustomCSS:    config.CustomCSS,
-		Variables:    config.Variables,
-		ConfigJSON:   template.JS(configBytes),
-		HotReloadURL: config.HotReloadURL,
+	configBytes = funcs.inline(configBytes)
+
+	scriptURL, integrity := scriptSource(config, opts.basePath)
+
+	hotReloadURL := config.HotReloadURL
+	if hotReloadURL == "" && opts.hotReload {
+		hotReloadURL = opts.basePath + HotReloadPath
 	}
 
-	// Parse and execute template
-	tmpl, err := template.New("scalar").Parse(htmlTemplate)
-	if err != nil {
-		return "", err
+	// Prepare template data
+	data := TemplateData{
+		Title:           config.Title,
+		Description:     config.Description,
+		Favicon:         config.Favicon,
+		CustomCSS:       trustedCSS(config.CustomCSS),
+		Variables:       cssVariables(config.Variables),
+		ConfigJSON:      template.JS(configBytes),
+		HotReloadURL:    hotReloadURL,
+		ScriptURL:       scriptURL,
+		ScriptIntegrity: integrity,
+		Nonce:           opts.Nonce,
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := pageTemplate.Execute(&buf, data); err != nil {
 		return "", err
 	}
 
 	return buf.String(), nil
 }
+
+// trustedCSS marks configured CSS as safe for a <style> block. The config is
+// trusted, but a closing tag must not end the block early.
+func trustedCSS(css string) template.CSS {
+	return template.CSS(strings.ReplaceAll(css, "</", `<\/`))
+}
+
+// cssVariables converts CSS custom properties to trusted template values
+func cssVariables(vars map[string]string) map[string]template.CSS {
+	if len(vars) == 0 {
+		return nil
+	}
+	out := make(map[string]template.CSS, len(vars))
+	for name, value := range vars {
+		out[strings.TrimPrefix(name, "--")] = trustedCSS(value)
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package scalarui
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGetConfigReturnsCopy(t *testing.T) {
+	ui := New(NewConfig().WithTitle("Before").WithVariable("accent", "red"))
+
+	c := ui.GetConfig()
+	c.Title = "After"
+	c.Variables["accent"] = "blue"
+
+	if got := ui.GetConfig(); got.Title != "Before" || got.Variables["accent"] != "red" {
+		t.Errorf("mutating GetConfig() changed the live config: %+v", got)
+	}
+}
+
+func TestSetConfigCopies(t *testing.T) {
+	config := NewConfig().WithTitle("Before")
+	ui := New(nil)
+	ui.SetConfig(config)
+
+	config.Title = "After"
+	html, err := ui.Render()
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(html, "<title>Before</title>") {
+		t.Errorf("mutating the config after SetConfig changed the page")
+	}
+}
+
+func TestConcurrentRenderAndUpdate(t *testing.T) {
+	ui := New(NewConfig().WithTitle("v0"))
+	handler := ui.Mount("/docs")
+
+	const workers, rounds = 8, 50
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if _, err := ui.Render(); err != nil {
+					t.Errorf("Render: %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+				if rec.Code != http.StatusOK {
+					t.Errorf("GET /docs = %d", rec.Code)
+				}
+			}
+		}()
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				ui.Update(func(c *Config) {
+					c.Title = fmt.Sprintf("v%d-%d", w, i)
+					c.WithVariable("accent", c.Title)
+					c.WithServer("https://example.com", c.Title)
+				})
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				c := ui.GetConfig()
+				c.Variables["accent"] = "mutated"
+				ui.SetConfig(c)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUpdateIsNotLost(t *testing.T) {
+	ui := New(NewConfig())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ui.Update(func(c *Config) {
+				c.WithServer("https://example.com", "")
+			})
+		}()
+	}
+	wg.Wait()
+
+	if n := len(ui.GetConfig().Servers); n != 100 {
+		t.Errorf("got %d servers after 100 updates, want 100", n)
+	}
+}
+
+func TestRenderCacheInvalidatedByUpdate(t *testing.T) {
+	ui := New(NewConfig().WithTitle("Before"))
+	if _, err := ui.Render(); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	ui.Update(func(c *Config) { c.Title = "After" })
+
+	html, err := ui.Render()
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(html, "<title>After</title>") {
+		t.Errorf("Render served a stale page after Update")
+	}
+}
+
+func TestAuthProviderPrefillsPerRequest(t *testing.T) {
+	config, err := NewConfig().WithAuth(NewAuth().Prefer("BearerAuth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := New(config)
+	ui.SetAuthProvider(func(r *http.Request) (*AuthConfig, error) {
+		user := r.Header.Get("X-User")
+		if user == "" {
+			return nil, nil
+		}
+		return NewAuth().WithBearer("BearerAuth", "token-"+user), nil
+	})
+
+	get := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		rec := httptest.NewRecorder()
+		ui.ServeHTTP(rec, req)
+		return rec
+	}
+
+	alice := get("alice")
+	if body := alice.Body.String(); !strings.Contains(body, "token-alice") || !strings.Contains(body, `"preferredSecurityScheme": "BearerAuth"`) {
+		t.Errorf("alice's page is missing her token or the configured auth")
+	}
+	if cc := alice.Header().Get("Cache-Control"); cc != "no-store, private" {
+		t.Errorf("Cache-Control = %q, want no-store, private", cc)
+	}
+	if etag := alice.Header().Get("ETag"); etag != "" {
+		t.Errorf("prefilled page sent ETag %q", etag)
+	}
+
+	for _, user := range []string{"bob", ""} {
+		if body := get(user).Body.String(); strings.Contains(body, "token-alice") {
+			t.Errorf("page for %q contains alice's token", user)
+		}
+	}
+	if _, ok := ui.GetConfig().Authentication["securitySchemes"]; ok {
+		t.Errorf("prefill leaked into the shared config: %v", ui.GetConfig().Authentication)
+	}
+}
+
+// brokenScheme is a scheme whose prefill cannot be marshaled
+type brokenScheme struct{ *HTTPBearer }
+
+func (brokenScheme) MarshalJSON() ([]byte, error) { return nil, fmt.Errorf("broken") }
+
+func TestAuthMarshalError(t *testing.T) {
+	auth := NewAuth().WithScheme("BearerAuth", brokenScheme{&HTTPBearer{}})
+	if _, err := auth.Map(); err == nil {
+		t.Errorf("Map succeeded for an unmarshalable scheme")
+	}
+
+	config := NewConfig().WithAuthentication(map[string]interface{}{"preferredSecurityScheme": "Kept"})
+	if _, err := config.WithAuth(auth); err == nil {
+		t.Errorf("WithAuth succeeded for an unmarshalable scheme")
+	}
+	if config.Authentication["preferredSecurityScheme"] != "Kept" {
+		t.Errorf("failed WithAuth replaced Authentication with %v", config.Authentication)
+	}
+
+	ui := New(NewConfig())
+	ui.SetAuthProvider(func(*http.Request) (*AuthConfig, error) { return auth, nil })
+	rec := httptest.NewRecorder()
+	ui.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("GET with unmarshalable prefill = %d, want 500", rec.Code)
+	}
+}
@@ -0,0 +1,331 @@
+package scalarui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotSpec is returned when a document is not an OpenAPI or Swagger spec
+var ErrNotSpec = errors.New("not an OpenAPI document")
+
+// SpecFormat is the serialization a spec document is written in
+type SpecFormat string
+
+const (
+	FormatJSON SpecFormat = "json"
+	FormatYAML SpecFormat = "yaml"
+)
+
+// ContentType returns the MIME type the format is served with
+func (f SpecFormat) ContentType() string {
+	if f == FormatYAML {
+		return "application/x-yaml; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// SpecVersion is the OpenAPI major.minor version of a document
+type SpecVersion string
+
+const (
+	Swagger20 SpecVersion = "2.0"
+	OpenAPI30 SpecVersion = "3.0"
+	OpenAPI31 SpecVersion = "3.1"
+)
+
+// Spec is a loaded OpenAPI or Swagger document
+type Spec struct {
+	Format  SpecFormat             // Serialization of Raw
+	Version SpecVersion            // Detected OpenAPI version
+	Raw     []byte                 // Document as loaded
+	Doc     map[string]interface{} // Decoded document
+
+	// What Upgrade could not carry over and what Filter had to drop, as
+	// "<JSON pointer>: <message>"
+	Warnings []string
+
+	// Origin, kept so the document can be reloaded
+	fsys    fs.FS
+	path    string
+	bundled bool         // Built by BundleSpec
+	filters []SpecFilter // Applied again on reload
+}
+
+// ParseSpec decodes a JSON or YAML document and detects its OpenAPI version
+func ParseSpec(data []byte) (*Spec, error) {
+	format := detectFormat(data)
+	if format == "" {
+		return nil, fmt.Errorf("%w: document is empty", ErrNotSpec)
+	}
+
+	var doc map[string]interface{}
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON: %v", ErrNotSpec, err)
+		}
+	case FormatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("%w: invalid YAML: %v", ErrNotSpec, err)
+		}
+		m, ok := yamlValue(&node).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: top level is not a mapping", ErrNotSpec)
+		}
+		doc = m
+	}
+
+	version, err := detectVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Spec{
+		Format:  format,
+		Version: version,
+		Raw:     data,
+		Doc:     doc,
+	}, nil
+}
+
+// LoadSpecFile reads and parses a spec from disk
+func LoadSpecFile(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: load spec: %w", err)
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: %s: %w", path, err)
+	}
+	spec.path = path
+	return spec, nil
+}
+
+// LoadSpecFS reads and parses a spec from fsys
+func LoadSpecFS(fsys fs.FS, name string) (*Spec, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: load spec: %w", err)
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: %s: %w", name, err)
+	}
+	spec.fsys = fsys
+	spec.path = name
+	return spec, nil
+}
+
+// LoadSpecReader reads and parses a spec from r
+func LoadSpecReader(r io.Reader) (*Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: load spec: %w", err)
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: %w", err)
+	}
+	return spec, nil
+}
+
+// Reload reads the spec again from the file or fs.FS it was loaded from,
+// bundling and filtering it as before. Specs made by BundleSpec, Filter and
+// Upgrade keep the format and key order of their source and reload from its
+// origin.
+func (s *Spec) Reload() (*Spec, error) {
+	var (
+		fresh *Spec
+		err   error
+	)
+	switch {
+	case s.path == "":
+		return nil, errors.New("scalarui: spec has no file to reload from")
+	case s.bundled:
+		fresh, err = BundleSpec(s.fsys, s.path)
+	case s.fsys != nil:
+		fresh, err = LoadSpecFS(s.fsys, s.path)
+	default:
+		fresh, err = LoadSpecFile(s.path)
+	}
+	for _, f := range s.filters {
+		if err != nil {
+			break
+		}
+		fresh, err = fresh.Filter(f)
+	}
+	return fresh, err
+}
+
+// derive returns a spec for doc, a changed copy of s's document. It is
+// written in s's format with keys in s's order, and keeps s's warnings and
+// origin, so it reloads the way s does.
+func (s *Spec) derive(doc map[string]interface{}) (*Spec, error) {
+	var orig *yaml.Node
+	if s.Format == FormatJSON {
+		dec := json.NewDecoder(bytes.NewReader(s.Raw))
+		dec.UseNumber()
+		orig, _ = yamlNode(dec)
+	} else {
+		var node yaml.Node
+		if yaml.Unmarshal(s.Raw, &node) == nil {
+			orig = &node
+		}
+	}
+
+	data, err := orderedJSON(doc, orig)
+	if err != nil {
+		return nil, err
+	}
+	if s.Format == FormatYAML {
+		if data, err = JSONToYAML(data); err != nil {
+			return nil, err
+		}
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, err
+	}
+	spec.Warnings = append([]string(nil), s.Warnings...)
+	spec.fsys, spec.path, spec.bundled = s.fsys, s.path, s.bundled
+	spec.filters = append([]SpecFilter(nil), s.filters...)
+	return spec, nil
+}
+
+// Title returns info.title, if any
+func (s *Spec) Title() string {
+	info, _ := s.Doc["info"].(map[string]interface{})
+	title, _ := info["title"].(string)
+	return title
+}
+
+// MarshalJSON sends the document to Scalar as the original text, which it
+// parses in either format
+func (s *Spec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(s.Raw))
+}
+
+// specFromContent parses Config.Content or SourceConfig.Content into a Spec.
+// It returns nil for empty content.
+func specFromContent(content interface{}) (*Spec, error) {
+	var data []byte
+	switch v := content.(type) {
+	case nil:
+		return nil, nil
+	case *Spec:
+		return v, nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotSpec, err)
+		}
+		data = b
+	}
+	return ParseSpec(data)
+}
+
+// detectFormat tells JSON from YAML by the first significant character
+func detectFormat(data []byte) SpecFormat {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return ""
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		return FormatJSON
+	default:
+		return FormatYAML
+	}
+}
+
+// detectVersion reads the openapi or swagger field
+func detectVersion(doc map[string]interface{}) (SpecVersion, error) {
+	if v, ok := doc["openapi"]; ok {
+		s := fmt.Sprint(v)
+		switch {
+		case strings.HasPrefix(s, "3.0"):
+			return OpenAPI30, nil
+		case strings.HasPrefix(s, "3.1"):
+			return OpenAPI31, nil
+		}
+		return "", fmt.Errorf("%w: unsupported openapi version %q", ErrNotSpec, s)
+	}
+	if v, ok := doc["swagger"]; ok {
+		s := fmt.Sprint(v)
+		if s == "2.0" {
+			return Swagger20, nil
+		}
+		return "", fmt.Errorf("%w: unsupported swagger version %q", ErrNotSpec, s)
+	}
+	return "", fmt.Errorf("%w: missing \"openapi\" or \"swagger\" field", ErrNotSpec)
+}
+
+// yamlValue converts a YAML node to the values encoding/json produces, so
+// both formats decode to the same shapes. Keys are always strings and
+// timestamps stay as written.
+func yamlValue(n *yaml.Node) interface{} {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return yamlValue(n.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.ShortTag() == "!!merge" {
+				if merged, ok := yamlValue(value).(map[string]interface{}); ok {
+					for k, v := range merged {
+						if _, exists := m[k]; !exists {
+							m[k] = v
+						}
+					}
+				}
+				continue
+			}
+			m[key.Value] = yamlValue(value)
+		}
+		return m
+	case yaml.SequenceNode:
+		s := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			s[i] = yamlValue(item)
+		}
+		return s
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool", "!!int", "!!float":
+		var v interface{}
+		if err := n.Decode(&v); err == nil {
+			switch num := v.(type) {
+			case int:
+				return float64(num)
+			case int64:
+				return float64(num)
+			case uint64:
+				return float64(num)
+			}
+			return v
+		}
+	}
+	return n.Value
+}
@@ -0,0 +1,134 @@
+package scalarui
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  SpecFormat
+		version SpecVersion
+	}{
+		{"json 3.0", `{"openapi": "3.0.3", "info": {"title": "API", "version": "1"}}`, FormatJSON, OpenAPI30},
+		{"yaml 3.0", "openapi: 3.0.3\ninfo:\n  title: API\n  version: '1'\n", FormatYAML, OpenAPI30},
+		{"json 3.1", `{"openapi": "3.1.0", "info": {"title": "API", "version": "1"}}`, FormatJSON, OpenAPI31},
+		{"yaml 3.1", "\n# comment\nopenapi: 3.1.0\n", FormatYAML, OpenAPI31},
+		{"json 2.0", `  {"swagger": "2.0"}`, FormatJSON, Swagger20},
+		{"yaml 2.0", "swagger: \"2.0\"\n", FormatYAML, Swagger20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseSpec([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseSpec: %v", err)
+			}
+			if spec.Format != tt.format || spec.Version != tt.version {
+				t.Errorf("got %s %s, want %s %s", spec.Format, spec.Version, tt.format, tt.version)
+			}
+			if string(spec.Raw) != tt.data {
+				t.Errorf("Raw = %q, want the input", spec.Raw)
+			}
+		})
+	}
+}
+
+func TestParseSpecRejectsNonSpecs(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"replicas: 3\n",
+		`{"name": "not a spec"}`,
+		"- a\n- b\n",
+		"openapi: 4.0.0\n",
+		`{"openapi": "2.0"}`,
+		"swagger: \"1.2\"\n",
+		`{"openapi": `,
+	} {
+		if _, err := ParseSpec([]byte(data)); !errors.Is(err, ErrNotSpec) {
+			t.Errorf("ParseSpec(%q) error = %v, want ErrNotSpec", data, err)
+		}
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	yamlData, err := os.ReadFile("testdata/specs/petstore-swagger.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"api/openapi.yaml": {Data: yamlData},
+		"values.yaml":      {Data: []byte("replicas: 3\n")},
+	}
+
+	file, err := LoadSpecFile("testdata/specs/petstore-3.1.json")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	if file.Format != FormatJSON || file.Version != OpenAPI31 || file.path != "testdata/specs/petstore-3.1.json" {
+		t.Errorf("LoadSpecFile = %s %s from %q", file.Format, file.Version, file.path)
+	}
+
+	inFS, err := LoadSpecFS(fsys, "api/openapi.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFS: %v", err)
+	}
+	if inFS.Format != FormatYAML || inFS.Version != Swagger20 || inFS.fsys == nil || inFS.path != "api/openapi.yaml" {
+		t.Errorf("LoadSpecFS = %s %s from %q", inFS.Format, inFS.Version, inFS.path)
+	}
+
+	read, err := LoadSpecReader(strings.NewReader(string(yamlData)))
+	if err != nil {
+		t.Fatalf("LoadSpecReader: %v", err)
+	}
+	if read.Format != FormatYAML || read.Version != Swagger20 || read.path != "" {
+		t.Errorf("LoadSpecReader = %s %s from %q", read.Format, read.Version, read.path)
+	}
+
+	if _, err := LoadSpecFS(fsys, "values.yaml"); !errors.Is(err, ErrNotSpec) || !strings.Contains(err.Error(), "values.yaml") {
+		t.Errorf("LoadSpecFS(values.yaml) error = %v, want ErrNotSpec naming the file", err)
+	}
+	if _, err := LoadSpecFile("testdata/specs/missing.yaml"); err == nil || errors.Is(err, ErrNotSpec) {
+		t.Errorf("LoadSpecFile(missing) error = %v, want a read error", err)
+	}
+	if _, err := LoadSpecReader(strings.NewReader("openapi: 4.0.0\n")); !errors.Is(err, ErrNotSpec) {
+		t.Errorf("LoadSpecReader(4.0) error = %v, want ErrNotSpec", err)
+	}
+}
+
+func TestWithSpecBuilders(t *testing.T) {
+	fsys := fstest.MapFS{"openapi.json": {Data: []byte(`{"openapi": "3.0.3"}`)}}
+
+	builders := map[string]func(*Config) (*Config, error){
+		"file": func(c *Config) (*Config, error) { return c.WithSpecFile("testdata/specs/petstore-3.1.json") },
+		"fs":   func(c *Config) (*Config, error) { return c.WithSpecFS(fsys, "openapi.json") },
+		"reader": func(c *Config) (*Config, error) {
+			return c.WithSpecReader(strings.NewReader("swagger: \"2.0\"\n"))
+		},
+	}
+	for name, build := range builders {
+		config := NewConfig()
+		got, err := build(config)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != config {
+			t.Errorf("%s: builder returned another config", name)
+		}
+		if _, ok := config.Content.(*Spec); !ok {
+			t.Errorf("%s: Content = %T, want *Spec", name, config.Content)
+		}
+	}
+
+	config := NewConfig().WithContent("kept")
+	if _, err := config.WithSpecReader(strings.NewReader("replicas: 3\n")); !errors.Is(err, ErrNotSpec) {
+		t.Errorf("WithSpecReader error = %v, want ErrNotSpec", err)
+	}
+	if config.Content != "kept" {
+		t.Errorf("failed load replaced Content with %v", config.Content)
+	}
+}
@@ -0,0 +1,410 @@
+package scalarui
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// componentNameUnsafe matches characters not allowed in component names
+var componentNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Keys whose value is a schema, a list of schemas or a map of schemas
+var (
+	schemaKeys     = []string{"items", "additionalProperties", "not", "contains", "if", "then", "else", "propertyNames", "unevaluatedItems", "unevaluatedProperties"}
+	schemaListKeys = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeys  = []string{"properties", "patternProperties", "dependentSchemas", "$defs", "definitions"}
+	literalKeys    = []string{"example", "examples", "default", "enum", "const"}
+)
+
+// componentKinds are the kinds of reusable objects a bundle may hoist
+var componentKinds = []string{"schemas", "parameters", "responses", "requestBodies", "headers", "examples", "links", "callbacks", "pathItems"}
+
+// BundleSpec loads the spec at root from fsys and resolves every $ref into
+// another file, such as "./schemas/user.yaml#/User", so the result is a
+// single self-contained document.
+//
+// Referenced schemas, parameters, responses and other reusable objects are
+// added to components (definitions, parameters and responses for Swagger
+// 2.0) and referenced locally; everything else is inlined. References that
+// form a cycle are an error unless they go through components. Remote
+// http(s) references are kept as they are.
+func BundleSpec(fsys fs.FS, root string) (*Spec, error) {
+	spec, err := LoadSpecFS(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &specBundler{
+		fsys:    fsys,
+		root:    path.Clean(root),
+		version: spec.Version,
+		files:   map[string]interface{}{},
+		refs:    map[string]string{},
+		taken:   map[string]map[string]bool{},
+		added:   map[string]map[string]interface{}{},
+	}
+	for _, kind := range componentKinds {
+		if base := b.componentBase(kind); base != "" {
+			existing, _ := resolvePointer(spec.Doc, "#/"+base).(map[string]interface{})
+			b.taken[kind] = map[string]bool{}
+			for name := range existing {
+				b.taken[kind][name] = true
+			}
+		}
+	}
+
+	b.registerComponents(spec.Doc)
+
+	walked, err := b.walk(spec.Doc, b.root, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: bundle %s: %w", root, err)
+	}
+	if b.changed {
+		doc := walked.(map[string]interface{})
+		b.addComponents(doc)
+
+		if spec, err = spec.derive(doc); err != nil {
+			return nil, fmt.Errorf("scalarui: bundle %s: %w", root, err)
+		}
+	}
+
+	spec.fsys, spec.path, spec.bundled = fsys, root, true
+	return spec, nil
+}
+
+// WithBundledSpec bundles the spec at root in fsys and sets it as the document
+// content
+func (c *Config) WithBundledSpec(fsys fs.FS, root string) (*Config, error) {
+	spec, err := BundleSpec(fsys, root)
+	if err != nil {
+		return c, err
+	}
+	return c.WithSpec(spec), nil
+}
+
+type specBundler struct {
+	fsys    fs.FS
+	root    string
+	version SpecVersion
+
+	files   map[string]interface{}            // Decoded external files by path
+	refs    map[string]string                 // "file#fragment" -> local $ref
+	taken   map[string]map[string]bool        // Component names in use, by kind
+	added   map[string]map[string]interface{} // Components to add, by kind
+	stack   []string                          // Inlined references being walked
+	changed bool
+}
+
+// componentBase returns the pointer, without "#/", of the map reusable
+// objects of kind are stored in, or "" when they are inlined
+func (b *specBundler) componentBase(kind string) string {
+	if b.version == Swagger20 {
+		switch kind {
+		case "schemas":
+			return "definitions"
+		case "parameters", "responses":
+			return kind
+		}
+		return ""
+	}
+	switch kind {
+	case "schemas", "parameters", "responses", "requestBodies", "headers", "examples", "links", "callbacks":
+		return "components/" + kind
+	case "pathItems":
+		if b.version == OpenAPI31 {
+			return "components/pathItems"
+		}
+	}
+	return ""
+}
+
+// walk returns a copy of v, an object of kind in file, with external
+// references resolved. self is the local $ref of v when it is an entry of
+// the root document's components.
+func (b *specBundler) walk(v interface{}, file, kind, self string) (interface{}, error) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := node["$ref"].(string); ok {
+			return b.ref(node, ref, file, kind, self)
+		}
+		return b.walkObject(node, file, kind)
+	case []interface{}:
+		out := make([]interface{}, len(node))
+		for i, item := range node {
+			walked, err := b.walk(item, file, kind, "")
+			if err != nil {
+				return nil, err
+			}
+			out[i] = walked
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// walkObject walks the members of an object, working out the kind of each
+func (b *specBundler) walkObject(node map[string]interface{}, file, kind string) (interface{}, error) {
+	atRoot := file == b.root && kind == "" && (node["openapi"] != nil || node["swagger"] != nil)
+	out := make(map[string]interface{}, len(node))
+
+	// Sorted, so generated component names do not depend on map order
+	for _, key := range sortedKeys(node) {
+		child := node[key]
+		var err error
+		switch {
+		case kind == "schemas" && contains(literalKeys, key):
+			out[key] = child
+		case kind == "schemas" && contains(schemaKeys, key):
+			out[key], err = b.walk(child, file, "schemas", "")
+		case kind == "schemas" && (contains(schemaListKeys, key) || contains(schemaMapKeys, key)):
+			out[key], err = b.walkEntries(child, file, "schemas", "")
+		case kind == "schemas":
+			out[key], err = b.walk(child, file, "", "")
+		case kind == "callbacks":
+			out[key], err = b.walk(child, file, "pathItems", "")
+		case atRoot && key == "components":
+			out[key], err = b.walkComponents(child)
+		case atRoot && (key == "definitions" || key == "parameters" || key == "responses"):
+			// Swagger 2.0 reusable objects
+			entryKind := key
+			if key == "definitions" {
+				entryKind = "schemas"
+			}
+			out[key], err = b.walkEntries(child, file, entryKind, "#/"+key)
+		case key == "schema":
+			out[key], err = b.walk(child, file, "schemas", "")
+		case key == "requestBody":
+			out[key], err = b.walk(child, file, "requestBodies", "")
+		case key == "parameters", key == "responses", key == "headers", key == "examples", key == "links", key == "callbacks":
+			out[key], err = b.walkEntries(child, file, key, "")
+		case key == "paths" || key == "webhooks":
+			out[key], err = b.walkEntries(child, file, "pathItems", "")
+		case key == "example" || key == "default":
+			out[key] = child
+		default:
+			out[key], err = b.walk(child, file, "", "")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// walkComponents walks the root components, whose entries may themselves
+// be external references
+func (b *specBundler) walkComponents(v interface{}) (interface{}, error) {
+	components, ok := v.(map[string]interface{})
+	if !ok {
+		return v, nil
+	}
+	out := make(map[string]interface{}, len(components))
+	for _, kind := range sortedKeys(components) {
+		entries := components[kind]
+		var err error
+		if isExtension(kind) || kind == "securitySchemes" {
+			out[kind], err = b.walk(entries, b.root, "", "")
+		} else {
+			out[kind], err = b.walkEntries(entries, b.root, kind, "#/components/"+kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// walkEntries walks every value of a map or list as an object of kind. With
+// selfBase, map entries know their own local $ref.
+func (b *specBundler) walkEntries(v interface{}, file, kind, selfBase string) (interface{}, error) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(node))
+		for _, name := range sortedKeys(node) {
+			child := node[name]
+			self := ""
+			if selfBase != "" && !isExtension(name) {
+				self = pointer(selfBase, name)
+			}
+			walked, err := b.walk(child, file, kind, self)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = walked
+		}
+		return out, nil
+	case []interface{}:
+		return b.walk(node, file, kind, "")
+	}
+	return v, nil
+}
+
+// ref resolves a reference found in file
+func (b *specBundler) ref(node map[string]interface{}, ref, file, kind, self string) (interface{}, error) {
+	target, fragment, _ := strings.Cut(ref, "#")
+	switch {
+	case strings.Contains(target, "://"):
+		return node, nil
+	case target == "":
+		target = file
+	default:
+		target = path.Join(path.Dir(file), target)
+	}
+	if target == b.root {
+		// Local to the root document; sibling keys stay as written
+		return b.withRef(node, "#"+fragment), nil
+	}
+	b.changed = true
+
+	key := target + "#" + fragment
+	if self != "" {
+		// A root component that lives in another file takes its place
+		value, err := b.resolve(target, fragment)
+		if err != nil {
+			return nil, err
+		}
+		b.refs[key] = self
+		return b.walk(value, target, kind, "")
+	}
+	if local, ok := b.refs[key]; ok {
+		return b.withRef(node, local), nil
+	}
+
+	value, err := b.resolve(target, fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	switch base := b.componentBase(kind); {
+	case base != "":
+		name := b.name(kind, target, fragment)
+		local := pointer("#/"+base, name)
+		b.refs[key] = local
+		walked, err := b.walk(value, target, kind, "")
+		if err != nil {
+			return nil, err
+		}
+		if b.added[kind] == nil {
+			b.added[kind] = map[string]interface{}{}
+		}
+		b.added[kind][name] = walked
+		return b.withRef(node, local), nil
+	}
+
+	// Inlined, so a reference back to it would never end
+	for i, seen := range b.stack {
+		if seen == key {
+			return nil, fmt.Errorf("$ref cycle: %s -> %s", strings.Join(b.stack[i:], " -> "), key)
+		}
+	}
+	b.stack = append(b.stack, key)
+	defer func() { b.stack = b.stack[:len(b.stack)-1] }()
+	return b.walk(value, target, kind, "")
+}
+
+// registerComponents maps root components defined in other files to their
+// local names before anything else refers to those files
+func (b *specBundler) registerComponents(doc map[string]interface{}) {
+	for _, kind := range componentKinds {
+		base := b.componentBase(kind)
+		if base == "" {
+			continue
+		}
+		entries, _ := resolvePointer(doc, "#/"+base).(map[string]interface{})
+		for name, entry := range entries {
+			obj, ok := entry.(map[string]interface{})
+			if !ok {
+				// Boolean schemas (OpenAPI 3.1) refer to nothing
+				continue
+			}
+			ref, _ := obj["$ref"].(string)
+			target, fragment, _ := strings.Cut(ref, "#")
+			if target == "" || strings.Contains(target, "://") {
+				continue
+			}
+			key := path.Join(path.Dir(b.root), target) + "#" + fragment
+			if _, ok := b.refs[key]; !ok {
+				b.refs[key] = pointer("#/"+base, name)
+			}
+		}
+	}
+}
+
+// withRef copies a reference object, pointing it at ref
+func (b *specBundler) withRef(node map[string]interface{}, ref string) map[string]interface{} {
+	out := make(map[string]interface{}, len(node))
+	for k, v := range node {
+		out[k] = v
+	}
+	out["$ref"] = ref
+	return out
+}
+
+// resolve loads file and returns the value fragment points at
+func (b *specBundler) resolve(file, fragment string) (interface{}, error) {
+	doc, ok := b.files[file]
+	if !ok {
+		data, err := fs.ReadFile(b.fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		doc = yamlValue(&node)
+		b.files[file] = doc
+	}
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		return nil, fmt.Errorf("%s#%s: only JSON pointer fragments are supported", file, fragment)
+	}
+	value := resolvePointer(doc, "#"+fragment)
+	if value == nil {
+		return nil, fmt.Errorf("%s#%s does not resolve", file, fragment)
+	}
+	return value, nil
+}
+
+// name picks an unused component name from the fragment's last token, or
+// the file name for whole-file references
+func (b *specBundler) name(kind, file, fragment string) string {
+	name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	if i := strings.LastIndex(fragment, "/"); i >= 0 && i < len(fragment)-1 {
+		name = strings.NewReplacer("~1", "/", "~0", "~").Replace(fragment[i+1:])
+	}
+	name = componentNameUnsafe.ReplaceAllString(name, "_")
+
+	if b.taken[kind] == nil {
+		b.taken[kind] = map[string]bool{}
+	}
+	candidate := name
+	for n := 2; b.taken[kind][candidate]; n++ {
+		candidate = name + strconv.Itoa(n)
+	}
+	b.taken[kind][candidate] = true
+	return candidate
+}
+
+// addComponents stores the hoisted objects in the bundled document
+func (b *specBundler) addComponents(doc map[string]interface{}) {
+	for kind, objects := range b.added {
+		parent := doc
+		for _, token := range strings.Split(b.componentBase(kind), "/") {
+			child, ok := parent[token].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				parent[token] = child
+			}
+			parent = child
+		}
+		for name, value := range objects {
+			parent[name] = value
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package scalarui
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBundleSpec(t *testing.T) {
+	spec, err := BundleSpec(os.DirFS("testdata/bundle"), "openapi.yaml")
+	if err != nil {
+		t.Fatalf("BundleSpec: %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Errorf("bundled document is invalid:\n%v", err)
+	}
+	if strings.Contains(string(spec.Raw), ".yaml") {
+		t.Errorf("bundle still refers to other files:\n%s", spec.Raw)
+	}
+
+	want := map[string]interface{}{
+		"/paths/~1users/get/operationId":                                               "listUsers",
+		"/paths/~1users/get/parameters/0/$ref":                                         "#/components/parameters/PageSize",
+		"/paths/~1users/get/responses/200/content/application~1json/schema/items/$ref": "#/components/schemas/User",
+		"/paths/~1users/get/responses/default/content/application~1json/schema/$ref":   "#/components/schemas/Error",
+		"/paths/~1users~1{id}/get/responses/200/content/application~1json/schema/$ref": "#/components/schemas/User",
+		"/paths/~1users~1{id}/get/responses/default/$ref":                              "#/components/responses/Problem",
+		"/components/schemas/User/properties/manager/$ref":                             "#/components/schemas/User",
+		"/components/schemas/User/properties/address/$ref":                             "#/components/schemas/address",
+		"/components/schemas/User/properties/tags/items/$ref":                          "#/components/schemas/Tag2",
+		"/components/schemas/Tag/type":                                                 "string",
+		"/components/schemas/Tag2/properties/label/type":                               "string",
+		"/components/schemas/Error/properties/message/type":                            "string",
+		"/components/parameters/PageSize/schema/default":                               float64(20),
+	}
+	for ptr, value := range want {
+		if got := resolvePointer(spec.Doc, "#"+ptr); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %v, want %v", ptr, got, value)
+		}
+	}
+
+	reloaded, err := spec.Reload()
+	if err != nil || !reflect.DeepEqual(reloaded.Doc, spec.Doc) {
+		t.Errorf("Reload did not bundle again: %v", err)
+	}
+}
+
+func TestBundleSpecCycle(t *testing.T) {
+	_, err := BundleSpec(os.DirFS("testdata/bundle/cycle"), "openapi.yaml")
+	if err == nil || !strings.Contains(err.Error(), "$ref cycle: a.yaml# -> b.yaml# -> a.yaml#") {
+		t.Errorf("BundleSpec = %v, want a cycle error", err)
+	}
+}
+
+func TestBundleSpecBooleanSchemas(t *testing.T) {
+	spec, err := BundleSpec(os.DirFS("testdata/bundle/boolean"), "openapi.yaml")
+	if err != nil {
+		t.Fatalf("BundleSpec: %v", err)
+	}
+	want := map[string]interface{}{
+		"/components/schemas/Any":                                                 true,
+		"/components/schemas/Nothing":                                             false,
+		"/components/schemas/thing/additionalProperties/$ref":                     "#/components/schemas/Any",
+		"/paths/~1things/get/responses/200/content/application~1json/schema/$ref": "#/components/schemas/thing",
+	}
+	for ptr, value := range want {
+		if got := resolvePointer(spec.Doc, "#"+ptr); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %v, want %v", ptr, got, value)
+		}
+	}
+}
@@ -0,0 +1,653 @@
+package scalarui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Upgrade converts a Swagger 2.0 document to OpenAPI 3.0 and returns other
+// documents unchanged. It maps definitions to components, body and formData
+// parameters to requestBody, and host/basePath/schemes to servers. Anything
+// that has no OpenAPI 3 equivalent is dropped and listed in Warnings.
+func (s *Spec) Upgrade() (*Spec, error) {
+	if s.Version != Swagger20 {
+		return s, nil
+	}
+
+	c := &swaggerConverter{src: s.Doc}
+	doc := c.convert()
+
+	spec, err := s.derive(doc)
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: upgrade swagger: %w", err)
+	}
+	spec.Warnings = append(spec.Warnings, c.warnings...)
+	return spec, nil
+}
+
+// upgradeContent returns Swagger 2.0 content upgraded to OpenAPI 3.0, or nil
+// for any other content
+func upgradeContent(content interface{}) (*Spec, error) {
+	spec, err := specFromContent(content)
+	if err != nil || spec == nil || spec.Version != Swagger20 {
+		return nil, nil
+	}
+	return spec.Upgrade()
+}
+
+/* ------------------------------------------------------------- */
+/* Converter */
+/* ------------------------------------------------------------- */
+
+// swaggerParamFields are the Swagger 2.0 parameter fields that move into
+// the OpenAPI 3 schema
+var swaggerParamFields = []string{
+	"type", "format", "items", "default", "maximum", "exclusiveMaximum", "minimum",
+	"exclusiveMinimum", "maxLength", "minLength", "pattern", "maxItems", "minItems",
+	"uniqueItems", "enum", "multipleOf",
+}
+
+// Media types formData parameters are sent as
+const (
+	formURLEncoded = "application/x-www-form-urlencoded"
+	formMultipart  = "multipart/form-data"
+)
+
+type swaggerConverter struct {
+	src      map[string]interface{}
+	warnings []string
+}
+
+func (c *swaggerConverter) warnf(ptr, format string, args ...interface{}) {
+	c.warnings = append(c.warnings, ptr+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *swaggerConverter) convert() map[string]interface{} {
+	doc := map[string]interface{}{"openapi": "3.0.3"}
+	for key, value := range c.src {
+		switch {
+		case key == "info", key == "tags", key == "externalDocs", key == "security", isExtension(key):
+			doc[key] = value
+		}
+	}
+
+	doc["servers"] = c.servers()
+
+	consumes := stringList(c.src["consumes"])
+	produces := stringList(c.src["produces"])
+
+	paths := map[string]interface{}{}
+	srcPaths, _ := c.src["paths"].(map[string]interface{})
+	for _, p := range sortedKeys(srcPaths) {
+		item, _ := srcPaths[p].(map[string]interface{})
+		paths[p] = c.pathItem(pointer("/paths", p), item, consumes, produces)
+	}
+	doc["paths"] = paths
+
+	if components := c.components(consumes, produces); len(components) > 0 {
+		doc["components"] = components
+	}
+	return rewriteRefs(doc).(map[string]interface{})
+}
+
+// servers builds server URLs from host, basePath and schemes
+func (c *swaggerConverter) servers() []interface{} {
+	host, _ := c.src["host"].(string)
+	basePath, _ := c.src["basePath"].(string)
+	schemes := stringList(c.src["schemes"])
+
+	if host == "" {
+		if basePath == "" {
+			basePath = "/"
+		}
+		return []interface{}{map[string]interface{}{"url": basePath}}
+	}
+	if len(schemes) == 0 {
+		c.warnf("/schemes", "not set; assuming https")
+		schemes = []string{"https"}
+	}
+	var servers []interface{}
+	for _, scheme := range schemes {
+		if scheme != "http" && scheme != "https" {
+			c.warnf("/schemes", "%q is not supported and was dropped", scheme)
+			continue
+		}
+		servers = append(servers, map[string]interface{}{"url": scheme + "://" + host + basePath})
+	}
+	return servers
+}
+
+func (c *swaggerConverter) pathItem(ptr string, item map[string]interface{}, consumes, produces []string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range item {
+		if key == "$ref" || isExtension(key) {
+			out[key] = value
+		}
+	}
+
+	shared, _ := item["parameters"].([]interface{})
+	if params := c.parameters(ptr+"/parameters", shared); len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	for _, method := range httpMethods {
+		op, ok := item[method].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		out[method] = c.operation(pointer(ptr, method), op, shared, consumes, produces)
+	}
+	return out
+}
+
+func (c *swaggerConverter) operation(ptr string, op map[string]interface{}, shared []interface{}, consumes, produces []string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range op {
+		switch key {
+		case "tags", "summary", "description", "externalDocs", "operationId", "deprecated", "security":
+			out[key] = value
+		case "schemes":
+			c.warnf(ptr+"/schemes", "per-operation schemes are not supported and were dropped")
+		default:
+			if isExtension(key) {
+				out[key] = value
+			}
+		}
+	}
+	if v, ok := op["consumes"]; ok {
+		consumes = stringList(v)
+	}
+	if v, ok := op["produces"]; ok {
+		produces = stringList(v)
+	}
+
+	own, _ := op["parameters"].([]interface{})
+	if params := c.parameters(ptr+"/parameters", own); len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	// Body and form parameters, with the operation's overriding shared ones
+	body := c.bodyParameters(shared)
+	for name, param := range c.bodyParameters(own) {
+		body[name] = param
+	}
+	if rb := c.requestBody(ptr, body, consumes); rb != nil {
+		out["requestBody"] = rb
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	converted := map[string]interface{}{}
+	for _, code := range sortedKeys(responses) {
+		resp, _ := responses[code].(map[string]interface{})
+		converted[code] = c.response(pointer(ptr, "responses", code), resp, produces)
+	}
+	out["responses"] = converted
+	return out
+}
+
+// resolveParameter follows a "#/parameters/..." reference
+func (c *swaggerConverter) resolveParameter(v interface{}) (map[string]interface{}, bool) {
+	param, _ := v.(map[string]interface{})
+	if ref, ok := param["$ref"].(string); ok {
+		resolved, _ := resolvePointer(c.src, ref).(map[string]interface{})
+		return resolved, true
+	}
+	return param, false
+}
+
+// parameters converts non-body parameters; references to them are kept
+func (c *swaggerConverter) parameters(ptr string, params []interface{}) []interface{} {
+	var out []interface{}
+	for i, v := range params {
+		param, isRef := c.resolveParameter(v)
+		if param == nil {
+			c.warnf(fmt.Sprintf("%s/%d", ptr, i), "unresolved parameter reference dropped")
+			continue
+		}
+		if in := param["in"]; in == "body" || in == "formData" {
+			continue
+		}
+		if isRef {
+			out = append(out, v)
+			continue
+		}
+		out = append(out, c.parameter(fmt.Sprintf("%s/%d", ptr, i), param))
+	}
+	return out
+}
+
+// parameter converts a query, path or header parameter
+func (c *swaggerConverter) parameter(ptr string, param map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range param {
+		switch key {
+		case "name", "in", "description", "required", "allowEmptyValue":
+			out[key] = value
+		default:
+			if isExtension(key) {
+				out[key] = value
+			}
+		}
+	}
+	out["schema"] = c.paramSchema(ptr, param)
+
+	if format, ok := param["collectionFormat"].(string); ok && param["type"] == "array" {
+		switch format {
+		case "csv":
+			if param["in"] == "query" {
+				out["style"], out["explode"] = "form", false
+			}
+		case "multi":
+			out["style"], out["explode"] = "form", true
+		case "ssv":
+			out["style"] = "spaceDelimited"
+		case "pipes":
+			out["style"] = "pipeDelimited"
+		default:
+			c.warnf(ptr+"/collectionFormat", "%q has no OpenAPI 3 equivalent; using csv", format)
+		}
+	}
+	return out
+}
+
+// paramSchema builds a schema from the type fields of a parameter, header
+// or items object
+func (c *swaggerConverter) paramSchema(ptr string, param map[string]interface{}) map[string]interface{} {
+	if schema, ok := param["schema"].(map[string]interface{}); ok {
+		return convertSchema(schema)
+	}
+	schema := map[string]interface{}{}
+	for _, key := range swaggerParamFields {
+		if value, ok := param[key]; ok {
+			schema[key] = value
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		if format, ok := items["collectionFormat"]; ok && format != "csv" {
+			c.warnf(ptr+"/items/collectionFormat", "nested collectionFormat %v was dropped", format)
+		}
+		schema["items"] = c.paramSchema(ptr+"/items", items)
+	}
+	if schema["type"] == "file" {
+		schema["type"], schema["format"] = "string", "binary"
+	}
+	return schema
+}
+
+// bodyParameters returns the body and formData parameters by in and name
+func (c *swaggerConverter) bodyParameters(params []interface{}) map[string]map[string]interface{} {
+	out := map[string]map[string]interface{}{}
+	for _, v := range params {
+		param, _ := c.resolveParameter(v)
+		if in, _ := param["in"].(string); in == "body" || in == "formData" {
+			name, _ := param["name"].(string)
+			out[in+":"+name] = param
+		}
+	}
+	return out
+}
+
+// requestBody builds a requestBody from body or formData parameters
+func (c *swaggerConverter) requestBody(ptr string, params map[string]map[string]interface{}, consumes []string) map[string]interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var body map[string]interface{}
+	form := map[string]interface{}{}
+	var required []interface{}
+	hasFile := false
+	for _, key := range keys {
+		param := params[key]
+		if param["in"] == "body" {
+			body = param
+			continue
+		}
+		name, _ := param["name"].(string)
+		schema := c.paramSchema(ptr, param)
+		if desc, ok := param["description"]; ok {
+			schema["description"] = desc
+		}
+		if param["type"] == "file" {
+			hasFile = true
+		}
+		form[name] = schema
+		if param["required"] == true {
+			required = append(required, name)
+		}
+	}
+
+	if body != nil {
+		if len(form) > 0 {
+			c.warnf(ptr+"/parameters", "body and formData parameters are mixed; formData was dropped")
+		}
+		var mediaTypes []string
+		for _, mt := range consumes {
+			if mt != formURLEncoded && mt != formMultipart {
+				mediaTypes = append(mediaTypes, mt)
+			}
+		}
+		if len(mediaTypes) == 0 {
+			mediaTypes = []string{"application/json"}
+		}
+		schema, _ := body["schema"].(map[string]interface{})
+		content := map[string]interface{}{}
+		for _, mt := range mediaTypes {
+			content[mt] = map[string]interface{}{"schema": convertSchema(schema)}
+		}
+		out := map[string]interface{}{"content": content}
+		if desc, ok := body["description"]; ok {
+			out["description"] = desc
+		}
+		if body["required"] == true {
+			out["required"] = true
+		}
+		if name, ok := body["name"].(string); ok {
+			out["x-codegen-request-body-name"] = name
+		}
+		return out
+	}
+
+	var mediaTypes []string
+	for _, mt := range consumes {
+		if mt == formURLEncoded || mt == formMultipart {
+			mediaTypes = append(mediaTypes, mt)
+		}
+	}
+	switch {
+	case len(mediaTypes) == 0 && hasFile:
+		mediaTypes = []string{formMultipart}
+	case len(mediaTypes) == 0:
+		mediaTypes = []string{formURLEncoded}
+	}
+	if hasFile && !contains(mediaTypes, formMultipart) {
+		c.warnf(ptr+"/consumes", "file parameters need %s", formMultipart)
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": form}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	content := map[string]interface{}{}
+	for _, mt := range mediaTypes {
+		content[mt] = map[string]interface{}{"schema": schema}
+	}
+	return map[string]interface{}{"content": content}
+}
+
+// response converts a response, moving schema and examples under content
+func (c *swaggerConverter) response(ptr string, resp map[string]interface{}, produces []string) map[string]interface{} {
+	if ref, ok := resp["$ref"]; ok {
+		return map[string]interface{}{"$ref": ref}
+	}
+	out := map[string]interface{}{"description": resp["description"]}
+	if out["description"] == nil {
+		out["description"] = ""
+	}
+	for key, value := range resp {
+		if isExtension(key) {
+			out[key] = value
+		}
+	}
+
+	if headers, ok := resp["headers"].(map[string]interface{}); ok {
+		converted := map[string]interface{}{}
+		for _, name := range sortedKeys(headers) {
+			header, _ := headers[name].(map[string]interface{})
+			h := map[string]interface{}{"schema": c.paramSchema(pointer(ptr, "headers", name), header)}
+			if desc, ok := header["description"]; ok {
+				h["description"] = desc
+			}
+			converted[name] = h
+		}
+		out["headers"] = converted
+	}
+
+	examples, _ := resp["examples"].(map[string]interface{})
+	schema, hasSchema := resp["schema"].(map[string]interface{})
+	if !hasSchema && len(examples) == 0 {
+		return out
+	}
+
+	mediaTypes := produces
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+	content := map[string]interface{}{}
+	for _, mt := range mediaTypes {
+		media := map[string]interface{}{}
+		if hasSchema {
+			media["schema"] = convertSchema(schema)
+		}
+		content[mt] = media
+	}
+	for _, mt := range sortedKeys(examples) {
+		media, ok := content[mt].(map[string]interface{})
+		if !ok {
+			media = map[string]interface{}{}
+			content[mt] = media
+		}
+		media["example"] = examples[mt]
+	}
+	out["content"] = content
+	return out
+}
+
+// components converts definitions, global parameters and responses, and
+// security definitions
+func (c *swaggerConverter) components(consumes, produces []string) map[string]interface{} {
+	components := map[string]interface{}{}
+
+	if defs, ok := c.src["definitions"].(map[string]interface{}); ok && len(defs) > 0 {
+		schemas := map[string]interface{}{}
+		for name, def := range defs {
+			schema, _ := def.(map[string]interface{})
+			schemas[name] = convertSchema(schema)
+		}
+		components["schemas"] = schemas
+	}
+
+	if params, ok := c.src["parameters"].(map[string]interface{}); ok {
+		parameters := map[string]interface{}{}
+		bodies := map[string]interface{}{}
+		for _, name := range sortedKeys(params) {
+			param, _ := params[name].(map[string]interface{})
+			ptr := pointer("/parameters", name)
+			switch param["in"] {
+			case "body", "formData":
+				in, _ := param["in"].(string)
+				bodies[name] = c.requestBody(ptr, map[string]map[string]interface{}{in: param}, consumes)
+			default:
+				parameters[name] = c.parameter(ptr, param)
+			}
+		}
+		if len(parameters) > 0 {
+			components["parameters"] = parameters
+		}
+		if len(bodies) > 0 {
+			// Operations inline these; kept for external references
+			components["requestBodies"] = bodies
+		}
+	}
+
+	if responses, ok := c.src["responses"].(map[string]interface{}); ok && len(responses) > 0 {
+		converted := map[string]interface{}{}
+		for _, name := range sortedKeys(responses) {
+			resp, _ := responses[name].(map[string]interface{})
+			converted[name] = c.response(pointer("/responses", name), resp, produces)
+		}
+		components["responses"] = converted
+	}
+
+	if defs, ok := c.src["securityDefinitions"].(map[string]interface{}); ok && len(defs) > 0 {
+		schemes := map[string]interface{}{}
+		for _, name := range sortedKeys(defs) {
+			def, _ := defs[name].(map[string]interface{})
+			if scheme := c.securityScheme(pointer("/securityDefinitions", name), def); scheme != nil {
+				schemes[name] = scheme
+			}
+		}
+		components["securitySchemes"] = schemes
+	}
+	return components
+}
+
+func (c *swaggerConverter) securityScheme(ptr string, def map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range def {
+		if key == "description" || isExtension(key) {
+			out[key] = value
+		}
+	}
+
+	switch def["type"] {
+	case "basic":
+		out["type"], out["scheme"] = "http", "basic"
+	case "apiKey":
+		out["type"], out["name"], out["in"] = "apiKey", def["name"], def["in"]
+	case "oauth2":
+		flow := map[string]interface{}{"scopes": def["scopes"]}
+		if flow["scopes"] == nil {
+			flow["scopes"] = map[string]interface{}{}
+		}
+		var name string
+		switch def["flow"] {
+		case "implicit":
+			name = "implicit"
+			flow["authorizationUrl"] = def["authorizationUrl"]
+		case "password":
+			name = "password"
+			flow["tokenUrl"] = def["tokenUrl"]
+		case "application":
+			name = "clientCredentials"
+			flow["tokenUrl"] = def["tokenUrl"]
+		case "accessCode":
+			name = "authorizationCode"
+			flow["authorizationUrl"] = def["authorizationUrl"]
+			flow["tokenUrl"] = def["tokenUrl"]
+		default:
+			c.warnf(ptr+"/flow", "unknown oauth2 flow %v; scheme dropped", def["flow"])
+			return nil
+		}
+		out["type"] = "oauth2"
+		out["flows"] = map[string]interface{}{name: flow}
+	default:
+		c.warnf(ptr+"/type", "unknown type %v; scheme dropped", def["type"])
+		return nil
+	}
+	return out
+}
+
+/* ------------------------------------------------------------- */
+/* Schemas and References */
+/* ------------------------------------------------------------- */
+
+// convertSchema rewrites the Swagger-only parts of a schema: x-nullable,
+// string discriminators and the file type
+func convertSchema(schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		return map[string]interface{}{}
+	}
+	out := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "x-nullable":
+			out["nullable"] = value
+		case "discriminator":
+			if name, ok := value.(string); ok {
+				value = map[string]interface{}{"propertyName": name}
+			}
+			out[key] = value
+		case "properties", "definitions", "patternProperties":
+			props, _ := value.(map[string]interface{})
+			converted := make(map[string]interface{}, len(props))
+			for name, prop := range props {
+				p, _ := prop.(map[string]interface{})
+				converted[name] = convertSchema(p)
+			}
+			out[key] = converted
+		case "items", "additionalProperties", "not":
+			if sub, ok := value.(map[string]interface{}); ok {
+				value = convertSchema(sub)
+			}
+			out[key] = value
+		case "allOf", "anyOf", "oneOf":
+			list, _ := value.([]interface{})
+			converted := make([]interface{}, len(list))
+			for i, item := range list {
+				sub, _ := item.(map[string]interface{})
+				converted[i] = convertSchema(sub)
+			}
+			out[key] = converted
+		default:
+			out[key] = value
+		}
+	}
+	if out["type"] == "file" {
+		out["type"], out["format"] = "string", "binary"
+	}
+	return out
+}
+
+// swaggerRefs maps Swagger 2.0 reference prefixes to OpenAPI 3 ones
+var swaggerRefs = []struct{ from, to string }{
+	{"#/definitions/", "#/components/schemas/"},
+	{"#/parameters/", "#/components/parameters/"},
+	{"#/responses/", "#/components/responses/"},
+}
+
+// rewriteRefs returns a copy of v with every $ref, local or external,
+// pointing at components. The converted document shares values with the
+// source, which must not change.
+func rewriteRefs(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(node))
+		for key, value := range node {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				out[key] = rewriteRef(ref)
+				continue
+			}
+			out[key] = rewriteRefs(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(node))
+		for i, item := range node {
+			out[i] = rewriteRefs(item)
+		}
+		return out
+	}
+	return v
+}
+
+func rewriteRef(ref string) string {
+	file, fragment, found := strings.Cut(ref, "#")
+	if !found {
+		return ref
+	}
+	for _, r := range swaggerRefs {
+		if rest, ok := strings.CutPrefix("#"+fragment, r.from); ok {
+			return file + r.to + rest
+		}
+	}
+	return ref
+}
+
+// stringList converts a decoded JSON array of strings
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package scalarui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpecUpgrade(t *testing.T) {
+	swagger, err := LoadSpecFile("testdata/specs/petstore-swagger.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	spec, err := swagger.Upgrade()
+	if err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	if spec.Version != OpenAPI30 || spec.Format != FormatYAML {
+		t.Fatalf("Upgrade = %s %s, want OpenAPI 3.0 YAML", spec.Version, spec.Format)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Errorf("upgraded document is invalid:\n%v", err)
+	}
+	if _, ok := swagger.Doc["definitions"].(map[string]interface{})["Pet"]; !ok {
+		t.Errorf("Upgrade changed the source document")
+	}
+
+	want := map[string]interface{}{
+		"/servers/0/url": "https://petstore.example.com/v1",
+		"/x-logo":        "logo.png",
+		"/paths/~1pets/post/requestBody/content/application~1json/schema/$ref":                                      "#/components/schemas/Pet",
+		"/paths/~1pets/post/requestBody/required":                                                                   true,
+		"/paths/~1pets/get/parameters/0/style":                                                                      "form",
+		"/paths/~1pets/get/responses/200/content/application~1json/schema/items/$ref":                               "#/components/schemas/Pet",
+		"/paths/~1pets/get/responses/200/content/application~1json/example/0/name":                                  "Rex",
+		"/paths/~1pets/get/responses/200/headers/X-Total/schema/type":                                               "integer",
+		"/paths/~1pets~1{petId}/parameters/0/$ref":                                                                  "#/components/parameters/petId",
+		"/paths/~1pets~1{petId}/get/responses/404/$ref":                                                             "#/components/responses/NotFound",
+		"/paths/~1pets~1{petId}~1photo/post/requestBody/content/multipart~1form-data/schema/properties/file/format": "binary",
+		"/paths/~1pets~1{petId}~1photo/post/requestBody/content/multipart~1form-data/schema/required/0":             "file",
+		"/components/schemas/Pet/discriminator/propertyName":                                                        "kind",
+		"/components/schemas/Pet/properties/name/nullable":                                                          true,
+		"/components/securitySchemes/basic/scheme":                                                                  "basic",
+		"/components/securitySchemes/petstore_auth/flows/authorizationCode/tokenUrl":                                "https://auth.example.com/token",
+	}
+	for ptr, value := range want {
+		if got := resolvePointer(spec.Doc, "#"+ptr); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %v, want %v", ptr, got, value)
+		}
+	}
+
+	wantWarnings := []string{
+		`/schemes: "ws" is not supported and was dropped`,
+		`/paths/~1pets/get/parameters/1/collectionFormat: "tsv" has no OpenAPI 3 equivalent; using csv`,
+	}
+	if !reflect.DeepEqual(spec.Warnings, wantWarnings) {
+		t.Errorf("Warnings:\n got %q\nwant %q", spec.Warnings, wantWarnings)
+	}
+}
+
+func TestScalarUIUpgradesSwagger(t *testing.T) {
+	swagger, err := LoadSpecFile("testdata/specs/petstore-swagger.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	ui := New(NewConfig().WithSource(SourceConfig{Slug: "legacy", Content: swagger}))
+
+	rec := httptest.NewRecorder()
+	ui.Mount("/docs").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/legacy/openapi.yaml", nil))
+	if !strings.Contains(rec.Body.String(), "openapi: 3.0.3") {
+		t.Errorf("source was not upgraded:\n%s", rec.Body.String())
+	}
+	if spec := ui.GetConfig().Sources[0].Content.(*Spec); spec.Version != Swagger20 {
+		t.Errorf("GetConfig returns the upgraded document, not the one set")
+	}
+	if w := ui.Warnings(); len(w) != 2 || w[0] != `sources[0]: /schemes: "ws" is not supported and was dropped` {
+		t.Errorf("Warnings = %q", w)
+	}
+}
+
+func TestUpgradedSpecReloadsFiltered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swagger.yaml")
+	write := func(extra string) {
+		doc := "swagger: '2.0'\ninfo:\n  title: Legacy\n  version: '1'\npaths:\n" +
+			"  /secret:\n    x-internal: true\n    get:\n      responses:\n        '200':\n          description: OK\n" + extra
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("")
+
+	spec, err := LoadSpecFile(path)
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+	if spec, err = spec.Filter(SpecFilter{ExcludeInternal: true}); err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if spec, err = spec.Upgrade(); err != nil {
+		t.Fatalf("Upgrade: %v", err)
+	}
+	ui := New(NewConfig().WithSpec(spec))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := NewFileWatcher(path)
+	w.Interval, w.Debounce = 5*time.Millisecond, 10*time.Millisecond
+	ready := watchReady(w)
+	go ui.Watch(ctx, w)
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		ui.Mount("/docs").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil))
+		return rec.Body.String()
+	}
+	<-ready
+	write("  /public:\n    get:\n      responses:\n        '200':\n          description: OK\n")
+
+	waitFor(t, func() bool { return strings.Contains(get(), "/public") }, "edit was not picked up:\n%s", get())
+	if doc := get(); strings.Contains(doc, "/secret") {
+		t.Errorf("reload republished an x-internal path:\n%s", doc)
+	}
+}
@@ -0,0 +1,734 @@
+package scalarui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is a single problem found by a Validate call
+type ValidationError struct {
+	Document string // Which document, e.g. "content" or "sources[1]"; empty for the config itself
+	Pointer  string // JSON pointer to the offending value
+	Message  string
+}
+
+func (e ValidationError) Error() string {
+	ptr := e.Pointer
+	if ptr == "" {
+		ptr = "/"
+	}
+	if e.Document != "" {
+		return e.Document + ": " + ptr + ": " + e.Message
+	}
+	return ptr + ": " + e.Message
+}
+
+// ValidationErrors collects every problem found by a Validate call
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// orNil returns nil for an empty list so callers can compare with nil
+func (e ValidationErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks the document against the structure required by the
+// OpenAPI 3.0/3.1 (or Swagger 2.0) specification. It returns
+// ValidationErrors pointing at every problem, or nil.
+func (s *Spec) Validate() error {
+	v := &specValidator{doc: s.Doc, version: s.Version, operationIDs: map[string]string{}}
+	v.validate()
+	return v.errs.orNil()
+}
+
+// Validate runs Config.Validate and then parses and checks Config.Content
+// and every inline source document. Documents referenced only by URL are not
+// fetched. Prefilled authentication schemes must be declared by one of the
+// inline documents.
+func (s *ScalarUI) Validate() error {
+	config := s.config()
+
+	var errs ValidationErrors
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+
+	// Schemes declared by every inline document, for the auth block
+	schemes := map[string]interface{}{}
+	inline := false
+
+	check := func(name string, content interface{}) {
+		spec, err := specFromContent(content)
+		if err != nil {
+			errs = append(errs, ValidationError{Document: name, Message: err.Error()})
+			return
+		}
+		if spec == nil {
+			return
+		}
+		inline = true
+		for k, v := range securitySchemes(spec.Doc) {
+			schemes[k] = v
+		}
+		if err := spec.Validate(); err != nil {
+			for _, e := range err.(ValidationErrors) {
+				e.Document = name
+				errs = append(errs, e)
+			}
+		}
+	}
+
+	check("content", config.Content)
+	for i, src := range config.Sources {
+		check(fmt.Sprintf("sources[%d]", i), src.Content)
+	}
+
+	// URL-only specs are fetched by the browser, so there is nothing to
+	// check the prefilled schemes against
+	if inline && config.Authentication != nil {
+		errs = append(errs, validateAuthentication(config.Authentication, schemes)...)
+	}
+	return errs.orNil()
+}
+
+/* ------------------------------------------------------------- */
+/* Document Validator */
+/* ------------------------------------------------------------- */
+
+var (
+	versionPattern      = regexp.MustCompile(`^3\.[01]\.\d+(-.+)?$`)
+	componentKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+	statusCodePattern   = regexp.MustCompile(`^([1-5](\d\d|XX)|default)$`)
+	pathParamPattern    = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var pathItemKeys = map[string]bool{
+	"$ref": true, "summary": true, "description": true, "servers": true, "parameters": true,
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+var rootKeys = map[SpecVersion]map[string]bool{
+	Swagger20: {
+		"swagger": true, "info": true, "host": true, "basePath": true, "schemes": true, "consumes": true,
+		"produces": true, "paths": true, "definitions": true, "parameters": true, "responses": true,
+		"securityDefinitions": true, "security": true, "tags": true, "externalDocs": true,
+	},
+	OpenAPI30: {
+		"openapi": true, "info": true, "servers": true, "paths": true, "components": true,
+		"security": true, "tags": true, "externalDocs": true,
+	},
+	OpenAPI31: {
+		"openapi": true, "info": true, "jsonSchemaDialect": true, "servers": true, "paths": true,
+		"webhooks": true, "components": true, "security": true, "tags": true, "externalDocs": true,
+	},
+}
+
+var componentSections = map[string]bool{
+	"schemas": true, "responses": true, "parameters": true, "examples": true, "requestBodies": true,
+	"headers": true, "securitySchemes": true, "links": true, "callbacks": true, "pathItems": true,
+}
+
+var schemaTypes = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true, "array": true, "object": true,
+}
+
+// specValidator walks a decoded document collecting errors
+type specValidator struct {
+	doc          map[string]interface{}
+	version      SpecVersion
+	errs         ValidationErrors
+	operationIDs map[string]string // operationId -> pointer of first use
+}
+
+func (v *specValidator) addf(ptr, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *specValidator) validate() {
+	if v.version == OpenAPI30 || v.version == OpenAPI31 {
+		if s, ok := v.doc["openapi"].(string); !ok || !versionPattern.MatchString(s) {
+			v.addf("/openapi", "must be a version string like \"3.0.3\"")
+		}
+	}
+
+	for _, key := range sortedKeys(v.doc) {
+		if !rootKeys[v.version][key] && !isExtension(key) {
+			v.addf(pointer("", key), "unknown field")
+		}
+	}
+
+	v.info()
+	v.servers("/servers", v.doc["servers"])
+	v.tags()
+
+	paths, hasPaths := v.doc["paths"]
+	switch {
+	case hasPaths:
+		v.paths(paths)
+	case v.version == OpenAPI31:
+		if v.doc["components"] == nil && v.doc["webhooks"] == nil {
+			v.addf("", "must contain at least one of paths, components or webhooks")
+		}
+	default:
+		v.addf("/paths", "is required")
+	}
+
+	if v.version == Swagger20 {
+		v.schemaMap("/definitions", v.doc["definitions"])
+	} else {
+		v.components()
+	}
+	v.security("/security", v.doc["security"])
+	v.refs("", v.doc)
+}
+
+func (v *specValidator) info() {
+	info, ok := v.object("/info", v.doc["info"], true)
+	if !ok {
+		return
+	}
+	v.requiredString("/info/title", info["title"])
+	v.requiredString("/info/version", info["version"])
+	if license, ok := v.object("/info/license", info["license"], false); ok {
+		v.requiredString("/info/license/name", license["name"])
+	}
+}
+
+func (v *specValidator) servers(ptr string, value interface{}) {
+	list, ok := v.array(ptr, value, false)
+	if !ok {
+		return
+	}
+	for i, item := range list {
+		p := pointer(ptr, fmt.Sprint(i))
+		server, ok := v.object(p, item, true)
+		if !ok {
+			continue
+		}
+		v.requiredString(p+"/url", server["url"])
+		vars, _ := v.object(p+"/variables", server["variables"], false)
+		for _, name := range sortedKeys(vars) {
+			vp := pointer(p+"/variables", name)
+			if variable, ok := v.object(vp, vars[name], true); ok {
+				v.requiredString(vp+"/default", variable["default"])
+				if enum, ok := variable["enum"].([]interface{}); ok && len(enum) == 0 {
+					v.addf(vp+"/enum", "must not be empty")
+				}
+			}
+		}
+	}
+}
+
+func (v *specValidator) tags() {
+	list, ok := v.array("/tags", v.doc["tags"], false)
+	if !ok {
+		return
+	}
+	seen := map[string]bool{}
+	for i, item := range list {
+		p := pointer("/tags", fmt.Sprint(i))
+		tag, ok := v.object(p, item, true)
+		if !ok {
+			continue
+		}
+		name, ok := v.requiredString(p+"/name", tag["name"])
+		if ok && seen[name] {
+			v.addf(p+"/name", "duplicate tag %q", name)
+		}
+		seen[name] = true
+	}
+}
+
+func (v *specValidator) paths(value interface{}) {
+	paths, ok := v.object("/paths", value, true)
+	if !ok {
+		return
+	}
+	for _, path := range sortedKeys(paths) {
+		if isExtension(path) {
+			continue
+		}
+		p := pointer("/paths", path)
+		if !strings.HasPrefix(path, "/") {
+			v.addf(p, "path must begin with \"/\"")
+		}
+		v.pathItem(p, path, paths[path])
+	}
+}
+
+func (v *specValidator) pathItem(ptr, path string, value interface{}) {
+	item, ok := v.object(ptr, value, true)
+	if !ok {
+		return
+	}
+	for _, key := range sortedKeys(item) {
+		if !pathItemKeys[key] && !isExtension(key) {
+			v.addf(pointer(ptr, key), "unknown field")
+		}
+	}
+	if _, isRef := item["$ref"]; isRef {
+		return
+	}
+
+	shared := v.parameters(ptr+"/parameters", item["parameters"])
+	v.servers(ptr+"/servers", item["servers"])
+
+	for _, method := range httpMethods {
+		if op, ok := item[method]; ok {
+			v.operation(pointer(ptr, method), path, op, shared)
+		}
+	}
+}
+
+func (v *specValidator) operation(ptr, path string, value interface{}, shared map[string]bool) {
+	op, ok := v.object(ptr, value, true)
+	if !ok {
+		return
+	}
+
+	if id, ok := op["operationId"].(string); ok {
+		if first, dup := v.operationIDs[id]; dup {
+			v.addf(ptr+"/operationId", "duplicate operationId %q (first used at %s)", id, first)
+		} else {
+			v.operationIDs[id] = ptr + "/operationId"
+		}
+	}
+
+	declared := v.parameters(ptr+"/parameters", op["parameters"])
+	for _, m := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		if !declared[m[1]] && !shared[m[1]] {
+			v.addf(ptr, "path parameter %q is not declared", m[1])
+		}
+	}
+
+	if body, ok := v.object(ptr+"/requestBody", op["requestBody"], false); ok && body["$ref"] == nil {
+		if _, ok := v.object(ptr+"/requestBody/content", body["content"], true); ok {
+			v.content(ptr+"/requestBody/content", body["content"])
+		}
+	}
+
+	responses, hasResponses := op["responses"]
+	if !hasResponses {
+		if v.version != OpenAPI31 {
+			v.addf(ptr+"/responses", "is required")
+		}
+	} else {
+		v.responses(ptr+"/responses", responses)
+	}
+
+	v.security(ptr+"/security", op["security"])
+}
+
+// parameters validates a parameter list and returns the names of the
+// declared path parameters
+func (v *specValidator) parameters(ptr string, value interface{}) map[string]bool {
+	declared := map[string]bool{}
+	list, ok := v.array(ptr, value, false)
+	if !ok {
+		return declared
+	}
+	for i, item := range list {
+		p := pointer(ptr, fmt.Sprint(i))
+		param, ok := v.object(p, item, true)
+		if !ok {
+			continue
+		}
+		if ref, ok := param["$ref"].(string); ok {
+			// A referenced path parameter counts as declared
+			if target, ok := resolvePointer(v.doc, ref).(map[string]interface{}); ok && target["in"] == "path" {
+				if name, ok := target["name"].(string); ok {
+					declared[name] = true
+				}
+			}
+			continue
+		}
+		v.parameter(p, param)
+		if param["in"] == "path" {
+			if name, ok := param["name"].(string); ok {
+				declared[name] = true
+			}
+		}
+	}
+	return declared
+}
+
+func (v *specValidator) parameter(ptr string, param map[string]interface{}) {
+	v.requiredString(ptr+"/name", param["name"])
+	in, ok := v.requiredString(ptr+"/in", param["in"])
+	if !ok {
+		return
+	}
+
+	locations := []string{"query", "header", "path", "cookie"}
+	if v.version == Swagger20 {
+		locations = []string{"query", "header", "path", "formData", "body"}
+	}
+	if !contains(locations, in) {
+		v.addf(ptr+"/in", "must be one of %s", strings.Join(locations, ", "))
+	}
+	if in == "path" && param["required"] != true {
+		v.addf(ptr+"/required", "must be true for path parameters")
+	}
+
+	if v.version == Swagger20 {
+		return
+	}
+	_, hasSchema := param["schema"]
+	_, hasContent := param["content"]
+	switch {
+	case hasSchema && hasContent:
+		v.addf(ptr, "must not define both schema and content")
+	case hasSchema:
+		v.schema(ptr+"/schema", param["schema"])
+	case hasContent:
+		v.content(ptr+"/content", param["content"])
+	default:
+		v.addf(ptr, "must define schema or content")
+	}
+}
+
+func (v *specValidator) responses(ptr string, value interface{}) {
+	responses, ok := v.object(ptr, value, true)
+	if !ok {
+		return
+	}
+	if len(responses) == 0 {
+		v.addf(ptr, "must contain at least one response")
+	}
+	for _, code := range sortedKeys(responses) {
+		if isExtension(code) {
+			continue
+		}
+		p := pointer(ptr, code)
+		if !statusCodePattern.MatchString(code) {
+			v.addf(p, "invalid status code %q", code)
+		}
+		v.response(p, responses[code])
+	}
+}
+
+func (v *specValidator) response(ptr string, value interface{}) {
+	resp, ok := v.object(ptr, value, true)
+	if !ok || resp["$ref"] != nil {
+		return
+	}
+	v.requiredString(ptr+"/description", resp["description"])
+	if v.version == Swagger20 {
+		v.schema(ptr+"/schema", resp["schema"])
+		return
+	}
+	v.content(ptr+"/content", resp["content"])
+}
+
+// content validates a media type map
+func (v *specValidator) content(ptr string, value interface{}) {
+	media, _ := v.object(ptr, value, false)
+	for _, name := range sortedKeys(media) {
+		p := pointer(ptr, name)
+		if mt, ok := v.object(p, media[name], true); ok {
+			v.schema(p+"/schema", mt["schema"])
+		}
+	}
+}
+
+func (v *specValidator) components() {
+	components, ok := v.object("/components", v.doc["components"], false)
+	if !ok {
+		return
+	}
+	for _, section := range sortedKeys(components) {
+		p := pointer("/components", section)
+		if isExtension(section) {
+			continue
+		}
+		if !componentSections[section] || (section == "pathItems" && v.version != OpenAPI31) {
+			v.addf(p, "unknown component section")
+			continue
+		}
+		entries, ok := v.object(p, components[section], true)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(entries) {
+			if !componentKeyPattern.MatchString(name) {
+				v.addf(pointer(p, name), "component name must match %s", componentKeyPattern)
+			}
+		}
+	}
+
+	v.schemaMap("/components/schemas", components["schemas"])
+	if schemes, ok := components["securitySchemes"].(map[string]interface{}); ok {
+		for _, name := range sortedKeys(schemes) {
+			v.securityScheme(pointer("/components/securitySchemes", name), schemes[name])
+		}
+	}
+}
+
+func (v *specValidator) securityScheme(ptr string, value interface{}) {
+	scheme, ok := v.object(ptr, value, true)
+	if !ok || scheme["$ref"] != nil {
+		return
+	}
+	typ, ok := v.requiredString(ptr+"/type", scheme["type"])
+	if !ok {
+		return
+	}
+	switch typ {
+	case "apiKey":
+		v.requiredString(ptr+"/name", scheme["name"])
+		if in, ok := v.requiredString(ptr+"/in", scheme["in"]); ok && !contains([]string{"query", "header", "cookie"}, in) {
+			v.addf(ptr+"/in", "must be one of query, header, cookie")
+		}
+	case "http":
+		v.requiredString(ptr+"/scheme", scheme["scheme"])
+	case "oauth2":
+		v.object(ptr+"/flows", scheme["flows"], true)
+	case "openIdConnect":
+		v.requiredString(ptr+"/openIdConnectUrl", scheme["openIdConnectUrl"])
+	case "mutualTLS":
+		if v.version != OpenAPI31 {
+			v.addf(ptr+"/type", "mutualTLS requires OpenAPI 3.1")
+		}
+	default:
+		v.addf(ptr+"/type", "must be one of apiKey, http, oauth2, openIdConnect, mutualTLS")
+	}
+}
+
+// security checks that every requirement names a declared scheme
+func (v *specValidator) security(ptr string, value interface{}) {
+	list, ok := v.array(ptr, value, false)
+	if !ok {
+		return
+	}
+	declared := securitySchemes(v.doc)
+	for i, item := range list {
+		p := pointer(ptr, fmt.Sprint(i))
+		req, ok := v.object(p, item, true)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(req) {
+			if _, ok := declared[name]; !ok {
+				v.addf(pointer(p, name), "security scheme %q is not declared", name)
+			}
+		}
+	}
+}
+
+func (v *specValidator) schemaMap(ptr string, value interface{}) {
+	schemas, _ := v.object(ptr, value, false)
+	for _, name := range sortedKeys(schemas) {
+		v.schema(pointer(ptr, name), schemas[name])
+	}
+}
+
+func (v *specValidator) schema(ptr string, value interface{}) {
+	if value == nil {
+		return
+	}
+	if _, isBool := value.(bool); isBool && v.version == OpenAPI31 {
+		return
+	}
+	schema, ok := v.object(ptr, value, true)
+	if !ok || (schema["$ref"] != nil && v.version != OpenAPI31) {
+		return
+	}
+
+	switch t := schema["type"].(type) {
+	case nil:
+	case string:
+		if !schemaTypes[t] && !(t == "null" && v.version == OpenAPI31) && !(t == "file" && v.version == Swagger20) {
+			v.addf(ptr+"/type", "unknown type %q", t)
+		}
+	case []interface{}:
+		if v.version != OpenAPI31 {
+			v.addf(ptr+"/type", "must be a string before OpenAPI 3.1")
+		}
+		for i, item := range t {
+			if s, ok := item.(string); !ok || !(schemaTypes[s] || s == "null") {
+				v.addf(pointer(ptr+"/type", fmt.Sprint(i)), "unknown type %v", item)
+			}
+		}
+	default:
+		v.addf(ptr+"/type", "must be a string")
+	}
+
+	if required, ok := v.array(ptr+"/required", schema["required"], false); ok {
+		for i, item := range required {
+			if _, ok := item.(string); !ok {
+				v.addf(pointer(ptr+"/required", fmt.Sprint(i)), "must be a string")
+			}
+		}
+	}
+
+	v.schemaMap(ptr+"/properties", schema["properties"])
+	v.schema(ptr+"/items", schema["items"])
+	v.schema(ptr+"/not", schema["not"])
+	if _, isBool := schema["additionalProperties"].(bool); !isBool {
+		v.schema(ptr+"/additionalProperties", schema["additionalProperties"])
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		list, _ := v.array(pointer(ptr, key), schema[key], false)
+		for i, item := range list {
+			v.schema(pointer(ptr, key, fmt.Sprint(i)), item)
+		}
+	}
+}
+
+// refs checks that every local $ref resolves inside the document
+func (v *specValidator) refs(ptr string, value interface{}) {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := node["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+			if resolvePointer(v.doc, ref) == nil {
+				v.addf(ptr+"/$ref", "unresolved reference %q", ref)
+			}
+		}
+		for _, key := range sortedKeys(node) {
+			v.refs(pointer(ptr, key), node[key])
+		}
+	case []interface{}:
+		for i, item := range node {
+			v.refs(pointer(ptr, fmt.Sprint(i)), item)
+		}
+	}
+}
+
+/* ------------------------------------------------------------- */
+/* Type Checks */
+/* ------------------------------------------------------------- */
+
+func (v *specValidator) object(ptr string, value interface{}, required bool) (map[string]interface{}, bool) {
+	if value == nil {
+		if required {
+			v.addf(ptr, "is required")
+		}
+		return nil, false
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		v.addf(ptr, "must be an object")
+	}
+	return m, ok
+}
+
+func (v *specValidator) array(ptr string, value interface{}, required bool) ([]interface{}, bool) {
+	if value == nil {
+		if required {
+			v.addf(ptr, "is required")
+		}
+		return nil, false
+	}
+	a, ok := value.([]interface{})
+	if !ok {
+		v.addf(ptr, "must be an array")
+	}
+	return a, ok
+}
+
+func (v *specValidator) requiredString(ptr string, value interface{}) (string, bool) {
+	if value == nil {
+		v.addf(ptr, "is required")
+		return "", false
+	}
+	s, ok := value.(string)
+	if !ok {
+		v.addf(ptr, "must be a string")
+	}
+	return s, ok
+}
+
+/* ------------------------------------------------------------- */
+/* JSON Pointers */
+/* ------------------------------------------------------------- */
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// pointer appends escaped reference tokens to base
+func pointer(base string, tokens ...string) string {
+	for _, t := range tokens {
+		base += "/" + pointerEscaper.Replace(t)
+	}
+	return base
+}
+
+// resolvePointer returns the value a local "#/..." reference points at,
+// or nil if it does not resolve
+func resolvePointer(doc interface{}, ref string) interface{} {
+	ref = strings.TrimPrefix(ref, "#")
+	if ref == "" {
+		return doc
+	}
+	if !strings.HasPrefix(ref, "/") {
+		return nil
+	}
+
+	current := doc
+	for _, token := range strings.Split(ref[1:], "/") {
+		token = pointerUnescaper.Replace(token)
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			current = node[i]
+		default:
+			return nil
+		}
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// securitySchemes returns the security schemes declared in the document,
+// from components.securitySchemes or Swagger 2.0 securityDefinitions
+func securitySchemes(doc map[string]interface{}) map[string]interface{} {
+	schemes, _ := doc["securityDefinitions"].(map[string]interface{})
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		schemes, _ = components["securitySchemes"].(map[string]interface{})
+	}
+	return schemes
+}
+
+func isExtension(key string) bool {
+	return strings.HasPrefix(key, "x-")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
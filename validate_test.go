@@ -0,0 +1,169 @@
+package scalarui
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSpecValidateValidFixtures(t *testing.T) {
+	for _, path := range []string{
+		"demo/data/openapi.yaml",
+		"testdata/specs/petstore-3.1.json",
+	} {
+		t.Run(path, func(t *testing.T) {
+			spec, err := LoadSpecFile(path)
+			if err != nil {
+				t.Fatalf("LoadSpecFile: %v", err)
+			}
+			if err := spec.Validate(); err != nil {
+				t.Errorf("Validate:\n%v", err)
+			}
+		})
+	}
+}
+
+func TestSpecValidateReportsPointers(t *testing.T) {
+	spec, err := LoadSpecFile("testdata/specs/invalid.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+
+	var verrs ValidationErrors
+	if !errors.As(spec.Validate(), &verrs) {
+		t.Fatalf("Validate did not return ValidationErrors")
+	}
+
+	var got []string
+	for _, e := range verrs {
+		got = append(got, e.Pointer)
+	}
+	want := []string{
+		"/info/version",
+		"/paths/~1users~1{id}/get/parameters/0/in",
+		"/paths/~1users~1{id}/get",
+		"/paths/~1users~1{id}/delete/operationId",
+		"/paths/~1users~1{id}/delete/parameters/0/required",
+		"/paths/~1users~1{id}/delete/parameters/0/schema/type",
+		"/paths/~1users~1{id}/delete/responses",
+		"/paths/users",
+		"/security/0/ApiKey",
+		"/paths/~1users~1{id}/get/responses/200/content/application~1json/schema/$ref",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pointers:\n got %q\nwant %q\nerrors:\n%v", got, want, verrs)
+	}
+}
+
+func TestScalarUIValidateSources(t *testing.T) {
+	valid, err := LoadSpecFile("demo/data/openapi.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+
+	config := NewConfig().
+		WithSource(SourceConfig{Title: "Valid", Content: valid}).
+		WithSource(SourceConfig{Title: "Not a spec", Content: "hello: world"}).
+		WithSource(SourceConfig{Title: "Remote", URL: "https://example.com/openapi.json"})
+
+	var verrs ValidationErrors
+	if !errors.As(New(config).Validate(), &verrs) {
+		t.Fatalf("Validate did not return ValidationErrors")
+	}
+	if len(verrs) != 1 || verrs[0].Document != "sources[1]" {
+		t.Errorf("Validate = %v, want one error for sources[1]", verrs)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := NewConfig().Validate(); err != nil {
+		t.Fatalf("NewConfig().Validate() = %v", err)
+	}
+
+	config := NewConfig().
+		WithURL("/openapi.json").
+		WithContent("openapi: 3.0.0").
+		WithForceDarkModeState("system").
+		WithDocumentDownloadType("xml").
+		WithSource(SourceConfig{Slug: "a", URL: "/a.json", Default: true}).
+		WithSource(SourceConfig{Slug: "a", URL: "/b.json", Default: true})
+	config.Layout = "grid"
+
+	var verrs ValidationErrors
+	if !errors.As(config.Validate(), &verrs) {
+		t.Fatalf("Validate did not return ValidationErrors")
+	}
+
+	var got []string
+	for _, e := range verrs {
+		got = append(got, e.Pointer)
+	}
+	want := []string{
+		"/layout",
+		"/forceDarkModeState",
+		"/documentDownloadType",
+		"/content",
+		"/sources",
+		"/sources/1/slug",
+		"/sources",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pointers:\n got %q\nwant %q\nerrors:\n%v", got, want, verrs)
+	}
+}
+
+func TestAuthValidate(t *testing.T) {
+	spec, err := LoadSpecFile("demo/data/openapi.yaml")
+	if err != nil {
+		t.Fatalf("LoadSpecFile: %v", err)
+	}
+
+	valid := NewAuth().
+		WithBearer("BearerAuth", "token").
+		WithAPIKey("ApiKeyAuth", "key").
+		WithOAuth2("OAuth2", OAuth2Flows{
+			AuthorizationCode: &AuthorizationCodeFlow{ClientID: "docs", PKCE: PKCESHA256},
+		}).
+		Prefer("BearerAuth")
+	if err := valid.Validate(spec); err != nil {
+		t.Fatalf("Validate:\n%v", err)
+	}
+	if err := valid.Validate(nil); err == nil {
+		t.Errorf("Validate(nil) succeeded")
+	}
+
+	invalid := NewAuth().
+		WithBearer("ApiKeyAuth", "token").
+		WithBasic("BasicAuth", "user", "pass").
+		WithOAuth2("OAuth2", OAuth2Flows{
+			AuthorizationCode: &AuthorizationCodeFlow{PKCE: "S256"},
+			ClientCredentials: &ClientCredentialsFlow{ClientID: "docs"},
+		}).
+		WithScheme("BearerAuth", &APIKey{Value: "key"}).
+		Prefer("Missing")
+
+	config, err := NewConfig().WithSpec(spec).WithAuth(invalid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var verrs ValidationErrors
+	if !errors.As(New(config).Validate(), &verrs) {
+		t.Fatalf("Validate did not return ValidationErrors")
+	}
+
+	var got []string
+	for _, e := range verrs {
+		got = append(got, e.Pointer)
+	}
+	want := []string{
+		"/authentication/preferredSecurityScheme",
+		"/authentication/securitySchemes/ApiKeyAuth",
+		"/authentication/securitySchemes/BasicAuth",
+		"/authentication/securitySchemes/BearerAuth",
+		"/authentication/securitySchemes/OAuth2/flows/authorizationCode/x-usePkce",
+		"/authentication/securitySchemes/OAuth2/flows/clientCredentials",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pointers:\n got %q\nwant %q\nerrors:\n%v", got, want, verrs)
+	}
+}
@@ -0,0 +1,176 @@
+package scalarui
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Default Watcher timings
+const (
+	DefaultWatchInterval = 500 * time.Millisecond
+	DefaultWatchDebounce = 300 * time.Millisecond
+)
+
+// Watcher detects spec changes by polling file metadata, so it needs no cgo
+// or platform notification API. Content hashes confirm a change before it is
+// reported, and bursts of writes are debounced into a single notification.
+type Watcher struct {
+	Interval time.Duration // How often files are stat'ed
+	Debounce time.Duration // Quiet period after the last change before reporting
+
+	fsys  fs.FS
+	root  string // File or directory inside fsys
+	ready func() // Called once the first scan has taken the baseline
+}
+
+// fileState is what a poll remembers about one file
+type fileState struct {
+	size    int64
+	modTime time.Time
+	sum     [sha256.Size]byte
+}
+
+// NewFileWatcher watches a single file on disk
+func NewFileWatcher(path string) *Watcher {
+	return NewFSWatcher(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// NewFSWatcher watches root inside fsys; directories are watched recursively
+func NewFSWatcher(fsys fs.FS, root string) *Watcher {
+	return &Watcher{
+		Interval: DefaultWatchInterval,
+		Debounce: DefaultWatchDebounce,
+		fsys:     fsys,
+		root:     root,
+	}
+}
+
+// Run polls until ctx is done, calling onChange once per settled change.
+// It returns ctx.Err() on cancellation or the error of the first scan.
+func (w *Watcher) Run(ctx context.Context, onChange func()) error {
+	state, err := w.scan(nil)
+	if err != nil {
+		return err
+	}
+	if w.ready != nil {
+		w.ready()
+	}
+
+	ticker := time.NewTicker(w.interval())
+	defer ticker.Stop()
+
+	var pending bool
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			next, err := w.scan(state)
+			if err != nil {
+				// Editors may briefly remove a file while saving
+				continue
+			}
+			if !sameFiles(state, next) {
+				pending = true
+				lastChange = now
+			}
+			state = next
+
+			if pending && now.Sub(lastChange) >= w.Debounce {
+				pending = false
+				onChange()
+			}
+		}
+	}
+}
+
+// Watch reloads open pages whenever w reports a change. Specs loaded from a
+// file are re-read first; a spec that fails to parse mid-edit keeps the
+// previous version. It blocks like Run.
+func (s *ScalarUI) Watch(ctx context.Context, w *Watcher) error {
+	return w.Run(ctx, func() {
+		s.Update(func(c *Config) {
+			c.Content = reloadSpec(c.Content)
+			for i := range c.Sources {
+				c.Sources[i].Content = reloadSpec(c.Sources[i].Content)
+			}
+		})
+		s.Reload()
+	})
+}
+
+// reloadSpec re-reads file-backed specs and returns other content unchanged
+func reloadSpec(content interface{}) interface{} {
+	spec, ok := content.(*Spec)
+	if !ok || spec.path == "" {
+		return content
+	}
+	fresh, err := spec.Reload()
+	if err != nil {
+		return content
+	}
+	return fresh
+}
+
+func (w *Watcher) interval() time.Duration {
+	if w.Interval <= 0 {
+		return DefaultWatchInterval
+	}
+	return w.Interval
+}
+
+// scan stats every watched file, re-hashing only files whose size or
+// modification time differ from prev
+func (w *Watcher) scan(prev map[string]fileState) (map[string]fileState, error) {
+	next := make(map[string]fileState)
+
+	err := fs.WalkDir(w.fsys, w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		st := fileState{size: info.Size(), modTime: info.ModTime()}
+		if old, ok := prev[path]; ok && old.size == st.size && old.modTime.Equal(st.modTime) {
+			st.sum = old.sum
+		} else {
+			data, err := fs.ReadFile(w.fsys, path)
+			if err != nil {
+				return err
+			}
+			st.sum = sha256.Sum256(data)
+		}
+		next[path] = st
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scalarui: watch %s: %w", w.root, err)
+	}
+	return next, nil
+}
+
+// sameFiles reports whether both scans saw the same files with the same content
+func sameFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, st := range a {
+		if other, ok := b[path]; !ok || other.sum != st.sum {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package scalarui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// watchReady returns a channel closed once w has taken its baseline scan
+func watchReady(w *Watcher) <-chan struct{} {
+	ready := make(chan struct{})
+	w.ready = func() { close(ready) }
+	return ready
+}
+
+// waitFor polls cond until it holds, failing the test after a generous
+// deadline so slow machines do not flake
+func waitFor(t *testing.T, cond func() bool, format string, args ...interface{}) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf(format, args...)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// startWatcher runs w until the test ends and counts its callbacks. It
+// returns once the first scan has taken the baseline.
+func startWatcher(t *testing.T, w *Watcher) *atomic.Int32 {
+	t.Helper()
+	ready := watchReady(w)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Errorf("Run = %v, want context.Canceled", err)
+		}
+	})
+
+	var calls atomic.Int32
+	go func() { done <- w.Run(ctx, func() { calls.Add(1) }) }()
+	select {
+	case <-ready:
+	case err := <-done:
+		done <- err
+		t.Fatalf("Run = %v before the first scan", err)
+	}
+	return &calls
+}
+
+func TestWatcherDebouncesWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "openapi.yaml")
+	write := func(s string) {
+		if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("v0")
+
+	// The debounce is far wider than the spacing of the writes, so only a
+	// stall of over half a second could split the burst
+	const debounce = 500 * time.Millisecond
+	w := NewFSWatcher(os.DirFS(dir), ".")
+	w.Interval, w.Debounce = 5*time.Millisecond, debounce
+	calls := startWatcher(t, w)
+
+	for i := 1; i <= 4; i++ {
+		write("v" + strings.Repeat("x", i))
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitFor(t, func() bool { return calls.Load() > 0 }, "burst of writes was not reported")
+	time.Sleep(2 * debounce)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("burst of writes reported %d times, want 1", n)
+	}
+
+	// Same content with a new modification time is not a change
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * debounce)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("touching the file reported a change (%d calls)", n)
+	}
+}
+
+func TestWatcherMissingRoot(t *testing.T) {
+	w := NewFileWatcher(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := w.Run(context.Background(), func() {}); err == nil {
+		t.Errorf("Run on a missing file succeeded")
+	}
+}
+
+func TestScalarUIWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	write := func(title string) {
+		doc := "openapi: 3.0.3\ninfo:\n  title: " + title + "\n  version: '1'\npaths: {}\n"
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Before")
+
+	config, err := NewConfig().WithSpecFile(path)
+	if err != nil {
+		t.Fatalf("WithSpecFile: %v", err)
+	}
+	ui := New(config)
+	version := ui.Version()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := NewFileWatcher(path)
+	w.Interval, w.Debounce = 5*time.Millisecond, 10*time.Millisecond
+	ready := watchReady(w)
+	go ui.Watch(ctx, w)
+	<-ready
+
+	write("After")
+	get := func() string {
+		rec := httptest.NewRecorder()
+		ui.Mount("/docs").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil))
+		return rec.Body.String()
+	}
+	waitFor(t, func() bool { return strings.Contains(get(), "title: After") && ui.Version() != version },
+		"spec was not re-read or pages not reloaded:\n%s", get())
+}